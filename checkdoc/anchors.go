@@ -0,0 +1,52 @@
+package checkdoc
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// DeadAnchor is a link to an anchor that does not match any heading of the document it points to.
+type DeadAnchor struct {
+	Destination string `json:"destination"` // The link destination, as written in the document
+	Target      string `json:"target"`      // The document the link points to, relative to the root
+	Anchor      string `json:"anchor"`      // The anchor, without '#'
+}
+
+// FindDeadAnchors returns the links of the passed node pointing to an anchor of a document, including
+// the node itself, that has no heading with that anchor. Links to directories are resolved with implicitIndexes.
+// Anchors are compared with the anchors GitHub generates for headings: anchors of links to anything
+// else than the passed documents, or defined in HTML, are not checked.
+func FindDeadAnchors(
+	treeRoot string,
+	node LinkGraphNode,
+	documents map[string]LinkGraphNode,
+	implicitIndexes []string,
+) []DeadAnchor {
+	var deadAnchors []DeadAnchor
+	check := func(destination string, target string) {
+		_, anchor, hasAnchor := strings.Cut(destination, "#")
+		document, isDocument := documents[target]
+		if !hasAnchor || anchor == "" || !isDocument {
+			return
+		}
+		if !slices.ContainsFunc(document.Document.Headings, func(heading markdown.Heading) bool {
+			return strings.EqualFold(heading.Anchor, anchor)
+		}) {
+			deadAnchors = append(deadAnchors, DeadAnchor{Destination: destination, Target: target, Anchor: anchor})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, link := range node.Document.Links {
+		if strings.HasPrefix(link.Destination, "#") && !seen[link.Destination] {
+			seen[link.Destination] = true
+			check(link.Destination, node.RelativePath)
+		}
+	}
+	forEachLocalLink(treeRoot, node.RelativePath, node, func(destination string, normalized string) {
+		check(destination, ResolveImplicitIndex(treeRoot, implicitIndexes, normalized))
+	})
+	return deadAnchors
+}
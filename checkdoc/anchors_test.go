@@ -0,0 +1,31 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestFindDeadAnchors(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n## Usage\n\n[a](#usage) [b](#gone) [c](docs/guide.md#Set-Up) [d](docs/#missing)\n" +
+			"[e](docs/guide.md) [f](tools/run.sh#L3) [g](docs/gone.md#x)\n",
+		"docs/README.md": "# Docs\n",
+		"docs/guide.md":  "# Guide\n\n## Set up\n",
+		"tools/run.sh":   "",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	documents := make(map[string]LinkGraphNode)
+	for _, node := range nodes {
+		documents[node.RelativePath] = node
+	}
+
+	assert.Equal(t, []DeadAnchor{
+		{Destination: "#gone", Target: "README.md", Anchor: "gone"},
+		{Destination: "docs/#missing", Target: "docs/README.md", Anchor: "missing"},
+	}, FindDeadAnchors(treeRoot, documents["README.md"], documents, []string{"README.md"}))
+	assert.Empty(t, FindDeadAnchors(treeRoot, documents["docs/guide.md"], documents, []string{"README.md"}))
+}
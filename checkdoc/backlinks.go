@@ -0,0 +1,123 @@
+package checkdoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// Backlink is a link from a document to a given target.
+type Backlink struct {
+	DocumentPath string // Path of the document containing the link, relative to the root
+	Line         int    // Line of the link in the document, 0 if it could not be located in the source
+	Destination  string // The link destination, as written in the document
+}
+
+// FindBacklinks returns all links and images pointing to target, a file or directory relative to the root,
+// sorted by document and line. This includes links to anything within target if it is a directory,
+// and links to a directory that resolves to target through an implicit index.
+func FindBacklinks(
+	treeRoot string,
+	nodes []LinkGraphNode,
+	implicitIndexes []string,
+	parser markdown.Parser,
+	target string,
+) ([]Backlink, error) {
+	var backlinks []Backlink
+	sanitizedRoot := strings.TrimSuffix(treeRoot, "/") + "/"
+
+	for _, node := range nodes {
+		var destinations []string
+		links := slices.Concat(node.Document.Links, node.Document.Images,
+			definitionLinks(node.Document))
+		for _, link := range markdown.FilterLocalLinks(links) {
+			if slices.Contains(destinations, link.Destination) {
+				continue
+			}
+			normalized, err := normalizeLinksToRoot(sanitizedRoot, node.RelativePath,
+				keepLinksAsStrings([]markdown.Link{link}, true))
+			if err != nil {
+				continue
+			}
+			if pointsTo(treeRoot, implicitIndexes, normalized[0], target) {
+				destinations = append(destinations, link.Destination)
+			}
+		}
+		if len(destinations) == 0 {
+			continue
+		}
+
+		source, err := os.ReadFile(filepath.Join(treeRoot, node.RelativePath))
+		if err != nil {
+			return nil, err
+		}
+		for _, destination := range destinations {
+			lines := destinationLines(parser, source, destination)
+			if len(lines) == 0 {
+				lines = []int{0}
+			}
+			for _, line := range lines {
+				backlinks = append(backlinks, Backlink{DocumentPath: node.RelativePath, Line: line, Destination: destination})
+			}
+		}
+	}
+
+	slices.SortFunc(backlinks, func(a, b Backlink) int {
+		if a.DocumentPath != b.DocumentPath {
+			return strings.Compare(a.DocumentPath, b.DocumentPath)
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.Destination, b.Destination)
+	})
+	return backlinks, nil
+}
+
+// pointsTo tells if a normalized link points to target, to something within it, or to a directory
+// whose implicit index is target. Only the first existing index of a directory is the one links to it lead to.
+func pointsTo(treeRoot string, implicitIndexes []string, link string, target string) bool {
+	if link == target || strings.HasPrefix(link, target+"/") {
+		return true
+	}
+	return ResolveImplicitIndex(treeRoot, implicitIndexes, link) == target
+}
+
+// destinationLines returns the lines at which the passed link destination appears in a document's source.
+func destinationLines(parser markdown.Parser, source []byte, destination string) []int {
+	var lines []int
+	for _, offset := range LocateDestination(parser, source, destination) {
+		line := bytes.Count(source[:offset], []byte("\n")) + 1
+		if !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// LocateDestination returns the byte offsets at which the passed link destination appears in a document's source,
+// using the same rules as ApplyFixes to tell destinations from other text.
+func LocateDestination(parser markdown.Parser, source []byte, destination string) []int {
+	var offsets []int
+	if destination == "" {
+		return nil
+	}
+	source = markdown.BlankCode(parser, source)
+	for offset := 0; offset < len(source); {
+		found := bytes.Index(source[offset:], []byte(destination))
+		if found < 0 {
+			break
+		}
+		start := offset + found
+		end := start + len(destination)
+		if isDestinationStart(source[:start]) && isDestinationEnd(source[end:]) {
+			offsets = append(offsets, start)
+		}
+		offset = start + 1
+	}
+	return offsets
+}
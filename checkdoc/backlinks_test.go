@@ -0,0 +1,73 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestFindBacklinks(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[docs](docs/)\n\nSee [install](docs/install.md#setup).\n" +
+			"[again](docs/install.md) and [other](docs/other.md)\n\n[ref]: ./docs\n",
+		"docs/README.md":  "---\ntitle: Docs\n---\n\n# Docs\n\n[install](install.md) ![img](img/a.png)\n",
+		"docs/install.md": "# Install\n\n[root](../README.md)\n",
+		"docs/img/a.png":  "png",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	implicitIndexes := []string{"README.md"}
+
+	backlinks, err := FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "docs/install.md")
+	assert.NoError(t, err)
+	assert.Equal(t, []Backlink{
+		{DocumentPath: "README.md", Line: 5, Destination: "docs/install.md#setup"},
+		{DocumentPath: "README.md", Line: 6, Destination: "docs/install.md"},
+		{DocumentPath: "docs/README.md", Line: 7, Destination: "install.md"},
+	}, backlinks)
+
+	backlinks, err = FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "docs/README.md")
+	assert.NoError(t, err)
+	assert.Equal(t, []Backlink{
+		{DocumentPath: "README.md", Line: 3, Destination: "docs/"},
+		{DocumentPath: "README.md", Line: 8, Destination: "./docs"},
+	}, backlinks)
+
+	backlinks, err = FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "docs/img")
+	assert.NoError(t, err)
+	assert.Equal(t, []Backlink{{DocumentPath: "docs/README.md", Line: 7, Destination: "img/a.png"}}, backlinks)
+
+	backlinks, err = FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "nothing.md")
+	assert.NoError(t, err)
+	assert.Empty(t, backlinks)
+}
+
+func TestFindBacklinksFirstIndex(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":      "# Root\n\n[docs](docs/)\n",
+		"docs/README.md": "# Docs\n",
+		"docs/index.md":  "# Index\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	implicitIndexes := []string{"README.md", "index.md"}
+
+	backlinks, err := FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "docs/index.md")
+	assert.NoError(t, err)
+	assert.Empty(t, backlinks, "Links to a directory should only lead to its first existing index")
+	backlinks, err = FindBacklinks(treeRoot, nodes, implicitIndexes, blackfridayParser(t), "docs/README.md")
+	assert.NoError(t, err)
+	assert.Equal(t, []Backlink{{DocumentPath: "README.md", Line: 3, Destination: "docs/"}}, backlinks)
+}
+
+func TestLocateDestination(t *testing.T) {
+	source := []byte("[a](docs/a.md)\n\n```\n[a](docs/a.md)\n```\n\nPAGE=docs/a.md <img\n  alt=\"a\" src='docs/a.md'>\n")
+	assert.Equal(t, []int{4, 75}, LocateDestination(blackfridayParser(t), source, "docs/a.md"))
+	assert.Equal(t, []int{1, 8}, destinationLines(blackfridayParser(t), source, "docs/a.md"))
+
+	source = []byte("<img srcset=\"img/a.png, img/a.png 2x,img/a.png 3x\">\n\n[ref]:img/a.png\n")
+	assert.Equal(t, []int{13, 24, 37, 59}, LocateDestination(blackfridayParser(t), source, "img/a.png"),
+		"Every srcset candidate and definitions without a space should be found")
+}
@@ -0,0 +1,178 @@
+package checkdoc
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Markers delimiting the region of a BUILD file that holds the generated rules
+const (
+	BazelStartMarker = "# checkdoc:bazel:start"
+	BazelEndMarker   = "# checkdoc:bazel:end"
+)
+
+// Name of the generated filegroup holding the files referenced from the documentation
+const docReferencesFilegroup = "doc_references"
+
+// Names of BUILD files, by order of precedence
+var buildFileNames = []string{"BUILD.bazel", "BUILD"}
+
+// BazelPackage is a Bazel package holding files referenced from the documentation.
+type BazelPackage struct {
+	BuildFile string   // Path of the package's BUILD file, relative to the root
+	Files     []string // Referenced files, relative to the package, sorted
+}
+
+// GroupByBazelPackage groups the passed files, relative to the root, by the Bazel package they belong to,
+// ie, the closest directory containing a BUILD file. Packages are sorted by BUILD file.
+// Files that don't belong to any package within the root are returned on their own.
+func GroupByBazelPackage(treeRoot string, files []string) ([]BazelPackage, []string) {
+	byBuildFile := make(map[string][]string)
+	var outside []string
+	for _, file := range files {
+		buildFile, found := findBuildFile(treeRoot, filepath.Dir(file))
+		if !found {
+			outside = append(outside, file)
+			continue
+		}
+		relativeFile, err := filepath.Rel(filepath.Dir(buildFile), file)
+		if err != nil {
+			// Both paths are relative to the same root, this can't happen.
+			relativeFile = file
+		}
+		byBuildFile[buildFile] = append(byBuildFile[buildFile], filepath.ToSlash(relativeFile))
+	}
+
+	var packages []BazelPackage
+	for _, buildFile := range sortedKeys(byBuildFile) {
+		packageFiles := byBuildFile[buildFile]
+		slices.Sort(packageFiles)
+		packages = append(packages, BazelPackage{BuildFile: buildFile, Files: slices.Compact(packageFiles)})
+	}
+	return packages, outside
+}
+
+// findBuildFile returns the BUILD file of the package containing the passed directory, relative to the root.
+func findBuildFile(treeRoot string, directory string) (string, bool) {
+	for {
+		for _, name := range buildFileNames {
+			buildFile := filepath.Join(directory, name)
+			if info, err := os.Stat(filepath.Join(treeRoot, buildFile)); err == nil && !info.IsDir() {
+				return buildFile, true
+			}
+		}
+		if directory == "." {
+			return "", false
+		}
+		directory = filepath.Dir(directory)
+	}
+}
+
+// BazelRules generates the rules declaring the files of a package, to be used as data dependencies.
+func BazelRules(pkg BazelPackage) string {
+	var sb strings.Builder
+	sb.WriteString("exports_files([\n")
+	for _, file := range pkg.Files {
+		fmt.Fprintf(&sb, "    %q,\n", file)
+	}
+	sb.WriteString("])\n\nfilegroup(\n")
+	fmt.Fprintf(&sb, "    name = %q,\n    srcs = [\n", docReferencesFilegroup)
+	for _, file := range pkg.Files {
+		fmt.Fprintf(&sb, "        %q,\n", file)
+	}
+	sb.WriteString("    ],\n    visibility = [\"//visibility:public\"],\n)\n")
+	return sb.String()
+}
+
+// BuildFileUpdate is a BUILD file whose generated rules are out of date.
+type BuildFileUpdate struct {
+	Path    string // Path of the BUILD file, relative to the root
+	Source  []byte // Current content of the BUILD file
+	Updated []byte // Content of the BUILD file with up to date rules
+}
+
+// PlanBuildFileUpdates computes the BUILD files whose generated rules need to change for the passed packages.
+// The rules are kept between markers: files without them get them appended. BUILD files holding generated
+// rules for a package that no longer has referenced files get an empty region.
+func PlanBuildFileUpdates(treeRoot string, packages []BazelPackage) ([]BuildFileUpdate, error) {
+	rules := make(map[string]string)
+	for _, pkg := range packages {
+		rules[pkg.BuildFile] = BazelRules(pkg)
+	}
+	generated, err := findGeneratedBuildFiles(treeRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, buildFile := range generated {
+		if _, found := rules[buildFile]; !found {
+			rules[buildFile] = ""
+		}
+	}
+
+	var updates []BuildFileUpdate
+	for _, buildFile := range sortedKeys(rules) {
+		source, err := os.ReadFile(filepath.Join(treeRoot, buildFile))
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateBazelRegion(source, rules[buildFile])
+		if err != nil {
+			return nil, fmt.Errorf("could not update %s: %w", buildFile, err)
+		}
+		if !bytes.Equal(source, updated) {
+			updates = append(updates, BuildFileUpdate{Path: buildFile, Source: source, Updated: updated})
+		}
+	}
+	return updates, nil
+}
+
+func updateBazelRegion(source []byte, rules string) ([]byte, error) {
+	if bytes.Contains(source, []byte(BazelStartMarker)) {
+		return replaceRegion(source, "bazel", BazelStartMarker, BazelEndMarker, rules)
+	}
+	if rules == "" {
+		return source, nil
+	}
+	updated := slices.Clone(source)
+	if len(updated) > 0 && !bytes.HasSuffix(updated, []byte("\n")) {
+		updated = append(updated, '\n')
+	}
+	if len(updated) > 0 {
+		updated = append(updated, '\n')
+	}
+	return append(updated, BazelStartMarker+"\n"+rules+BazelEndMarker+"\n"...), nil
+}
+
+// findGeneratedBuildFiles returns the BUILD files below treeRoot that contain generated rules.
+func findGeneratedBuildFiles(treeRoot string) ([]string, error) {
+	var generated []string
+	err := filepath.WalkDir(treeRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == gitDirectory {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !slices.Contains(buildFileNames, d.Name()) {
+			return nil
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(source, []byte(BazelStartMarker)) {
+			relativePath, err := filepath.Rel(treeRoot, path)
+			if err != nil {
+				return err
+			}
+			generated = append(generated, filepath.ToSlash(relativePath))
+		}
+		return nil
+	})
+	return generated, err
+}
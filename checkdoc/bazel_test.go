@@ -0,0 +1,73 @@
+package checkdoc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestGroupByBazelPackage(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"tools/BUILD.bazel":     "",
+		"tools/BUILD":           "",
+		"tools/run.sh":          "",
+		"tools/lib/helper.sh":   "",
+		"tools/lib/sub/BUILD":   "",
+		"tools/lib/sub/data.sh": "",
+		"scripts/setup.sh":      "",
+	})
+
+	packages, outside := GroupByBazelPackage(treeRoot, []string{
+		"tools/run.sh", "tools/lib/sub/data.sh", "scripts/setup.sh", "tools/lib/helper.sh", "tools/run.sh",
+	})
+	assert.Equal(t, []BazelPackage{
+		{BuildFile: "tools/BUILD.bazel", Files: []string{"lib/helper.sh", "run.sh"}},
+		{BuildFile: "tools/lib/sub/BUILD", Files: []string{"data.sh"}},
+	}, packages)
+	assert.Equal(t, []string{"scripts/setup.sh"}, outside)
+}
+
+func TestBazelRules(t *testing.T) {
+	assert.Equal(t, `exports_files([
+    "lib/helper.sh",
+    "run.sh",
+])
+
+filegroup(
+    name = "doc_references",
+    srcs = [
+        "lib/helper.sh",
+        "run.sh",
+    ],
+    visibility = ["//visibility:public"],
+)
+`, BazelRules(BazelPackage{BuildFile: "tools/BUILD", Files: []string{"lib/helper.sh", "run.sh"}}))
+}
+
+func TestPlanBuildFileUpdates(t *testing.T) {
+	upToDate := BazelPackage{BuildFile: "current/BUILD", Files: []string{"a.sh"}}
+	treeRoot := testtree.Write(t, map[string]string{
+		"new/BUILD":     `sh_binary(name = "b", srcs = ["b.sh"])`,
+		"current/BUILD": "# checkdoc:bazel:start\n" + BazelRules(upToDate) + "# checkdoc:bazel:end\n",
+		"stale/BUILD":   "load(\"//x.bzl\", \"x\")\n# checkdoc:bazel:start\nexports_files([\"old.sh\"])\n# checkdoc:bazel:end\n",
+		"broken/BUILD":  "# checkdoc:bazel:start\n",
+	})
+
+	_, err := PlanBuildFileUpdates(treeRoot, nil)
+	assert.ErrorContains(t, err, "could not update broken/BUILD: missing bazel end marker")
+
+	assert.NoError(t, os.Remove(filepath.Join(treeRoot, "broken/BUILD")))
+	newPackage := BazelPackage{BuildFile: "new/BUILD", Files: []string{"b.sh"}}
+	updates, err := PlanBuildFileUpdates(treeRoot, []BazelPackage{upToDate, newPackage})
+	assert.NoError(t, err)
+	assert.Len(t, updates, 2)
+	assert.Equal(t, "new/BUILD", updates[0].Path)
+	assert.Equal(t, `sh_binary(name = "b", srcs = ["b.sh"])`+"\n\n# checkdoc:bazel:start\n"+
+		BazelRules(newPackage)+"# checkdoc:bazel:end\n", string(updates[0].Updated))
+	assert.Equal(t, "stale/BUILD", updates[1].Path)
+	assert.Equal(t, "load(\"//x.bzl\", \"x\")\n# checkdoc:bazel:start\n# checkdoc:bazel:end\n", string(updates[1].Updated))
+}
@@ -0,0 +1,178 @@
+package checkdoc
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// Options configures a Checker: where the documentation is, how to find and parse documents, and which checks
+// to run on top of the dead links and orphans, which are always reported. Checks are disabled by their zero value.
+type Options struct {
+	TreeRoot         string          // Absolute path to the root of the documentation
+	BaseNames        []string        // Names of the files that are documents, eg CHANGELOG, along with Extensions
+	Extensions       []string        // Extensions of the files that are documents, with the dot, eg .md
+	ImplicitIndexes  []string        // Files links to a directory lead to, eg README.md
+	RespectGitIgnore bool            // Leave out the files ignored by git. TreeRoot must then be in a git repository
+	Parser           markdown.Parser // Parser of the documents. The blackfriday backend is used if nil
+
+	Suggestions          bool              // Suggest what dead links and images may have been meant to point to
+	Images               ImageChecks       // Checks on the content of local images
+	StrictDirectoryLinks bool              // Links to directories must lead to an implicit index
+	ExplicitLinks        bool              // Links must point to documents, not to their directory or without extension
+	LinkStyle            *LinkStyle        // How local links are written, not checked if nil
+	RootDocuments        []string          // Where readers start browsing, relative to the root: they need no link to them
+	MaxDepth             int               // How many links away from the root documents documents may be. No limit if 0
+	IndexRequirements    IndexRequirements // Directories that must contain an implicit index
+}
+
+// DefaultOptions returns the options the checkdoc command uses by default for the passed tree root:
+// documents are the .md files not ignored by git, links to directories lead to their README.md,
+// readers start browsing at the root README.md, and dead links come with suggestions.
+func DefaultOptions(treeRoot string) Options {
+	return Options{
+		TreeRoot:         treeRoot,
+		Extensions:       []string{".md"},
+		ImplicitIndexes:  []string{"README.md"},
+		RespectGitIgnore: true,
+		RootDocuments:    []string{"README.md"},
+		Suggestions:      true,
+	}
+}
+
+// Checker finds the documents of a tree, builds the link graph between them and runs the checks on it,
+// as configured by its Options. It does not log anything: findings are returned as values.
+type Checker struct {
+	options Options
+}
+
+// Result holds what the checks found.
+type Result struct {
+	Reports                 map[string]NodeReport // The report of each document, by path relative to the root
+	DirectoriesWithoutIndex []string              // Sorted directories lacking a required index
+	Findings                []Finding             // Every finding of the reports and directories, sorted
+}
+
+// Valid tells if the checks found nothing.
+func (r Result) Valid() bool {
+	return len(r.Findings) == 0
+}
+
+// NewChecker returns a checker configured with the passed options, after validating them.
+func NewChecker(options Options) (*Checker, error) {
+	if !filepath.IsAbs(options.TreeRoot) {
+		return nil, fmt.Errorf("treeRoot must be absolute, was: %s", options.TreeRoot)
+	}
+	if len(options.BaseNames) == 0 && len(options.Extensions) == 0 {
+		return nil, fmt.Errorf("need to specify at least one base name or extension")
+	}
+	for _, extension := range options.Extensions {
+		if !strings.HasPrefix(extension, ".") {
+			return nil, fmt.Errorf("extension must start with a dot (.): %s", extension)
+		}
+	}
+	for _, pattern := range options.IndexRequirements.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid directory pattern %q: %w", pattern, err)
+		}
+	}
+	if options.Parser == nil {
+		parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+		if err != nil {
+			return nil, err
+		}
+		options.Parser = parser
+	}
+	return &Checker{options: options}, nil
+}
+
+// Options returns the options of the checker, with the defaults it picked.
+func (c *Checker) Options() Options {
+	return c.options
+}
+
+// Discover returns the sorted paths, relative to the root, of the documents of the tree.
+func (c *Checker) Discover(ctx context.Context) ([]string, error) {
+	absPaths, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sanitizedRoot := strings.TrimSuffix(c.options.TreeRoot, "/") + "/"
+	var paths []string
+	for _, absPath := range absPaths {
+		paths = append(paths, strings.TrimPrefix(absPath, sanitizedRoot))
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths), nil
+}
+
+func (c *Checker) discover(ctx context.Context) ([]string, error) {
+	absPaths, err := findMatchingFiles(ctx, c.options.TreeRoot, c.options.BaseNames, c.options.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	return filterGitIgnored(c.options.TreeRoot, absPaths, c.options.RespectGitIgnore)
+}
+
+// LinkGraph discovers and parses the documents of the tree, returning their nodes in the link graph.
+func (c *Checker) LinkGraph(ctx context.Context) ([]LinkGraphNode, error) {
+	absPaths, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseFilesAndBuildGraph(ctx, absPaths, c.options.TreeRoot, c.options.Parser)
+}
+
+// Check runs the configured checks on the passed nodes of the link graph, as returned by LinkGraph.
+// It stops with the error of ctx once ctx is done.
+func (c *Checker) Check(ctx context.Context, nodes []LinkGraphNode) (Result, error) {
+	options := c.options
+	reports := BuildReport(options.TreeRoot, nodes, options.ImplicitIndexes, options.RootDocuments)
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	if options.Suggestions {
+		SuggestTargets(options.TreeRoot, reports, options.RespectGitIgnore)
+	}
+	if err := CheckImages(options.TreeRoot, reports, options.Images); err != nil {
+		return Result{}, fmt.Errorf("could not check images: %w", err)
+	}
+	if options.StrictDirectoryLinks {
+		CheckDirectoryLinks(options.TreeRoot, reports, options.ImplicitIndexes)
+	}
+	if options.ExplicitLinks {
+		CheckExplicitLinks(options.TreeRoot, reports, options.ImplicitIndexes, options.Extensions)
+	}
+	if options.LinkStyle != nil {
+		CheckLinkStyle(options.TreeRoot, reports, *options.LinkStyle)
+	}
+	CheckDepth(options.TreeRoot, reports, options.ImplicitIndexes, options.RootDocuments, options.MaxDepth)
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	withoutIndex, err := FindDirectoriesWithoutIndex(options.TreeRoot, options.IndexRequirements,
+		options.ImplicitIndexes, options.RespectGitIgnore)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not check directory indexes: %w", err)
+	}
+	return Result{
+		Reports:                 reports,
+		DirectoriesWithoutIndex: withoutIndex,
+		Findings:                ListFindings(reports, withoutIndex),
+	}, nil
+}
+
+// Run builds the link graph of the tree and checks it.
+func (c *Checker) Run(ctx context.Context) (Result, error) {
+	nodes, err := c.LinkGraph(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Check(ctx, nodes)
+}
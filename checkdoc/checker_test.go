@@ -0,0 +1,97 @@
+package checkdoc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestChecker(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":         "# Root\n\n[guide](docs/guide.md) [gone](docs/gone.md) [up](/docs/../README.md)\n",
+		"docs/guide.md":     "# Guide\n",
+		"docs/orphan.md":    "# Orphan\n",
+		"services/db/BUILD": "",
+	})
+	options := DefaultOptions(treeRoot)
+	options.RespectGitIgnore = false
+	options.LinkStyle = &LinkStyle{ForbidRootAbsolute: true}
+	options.IndexRequirements = IndexRequirements{MarkerFiles: []string{"BUILD"}}
+	checker, err := NewChecker(options)
+	assert.NoError(t, err)
+	assert.NotNil(t, checker.Options().Parser, "A parser is picked by default")
+
+	documents, err := checker.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "docs/guide.md", "docs/orphan.md"}, documents)
+
+	result, err := checker.Run(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Valid())
+	assert.Equal(t, []string{"services/db"}, result.DirectoriesWithoutIndex)
+	assert.Equal(t, []Finding{
+		{Path: "README.md", Kind: DeadLinkFinding, Message: "docs/gone.md"},
+		{Path: "README.md", Kind: LinkStyleFinding, Message: "/docs/../README.md is root-absolute, use README.md instead"},
+		{Path: "docs/orphan.md", Kind: OrphanFinding, Message: "nothing links to this document"},
+		{Path: "services/db", Kind: RequiredIndexFinding, Message: "no index found"},
+	}, result.Findings)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = checker.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCheckerRootDocuments(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"index.md":      "# Index\n\n[guide](docs/guide.md)\n",
+		"docs/guide.md": "# Guide\n",
+	})
+	options := DefaultOptions(treeRoot)
+	options.RespectGitIgnore = false
+	options.RootDocuments = []string{"index.md"}
+	checker, err := NewChecker(options)
+	assert.NoError(t, err)
+
+	result, err := checker.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, result.Findings, "Root documents should not be reported as orphans")
+}
+
+func TestCheckerSuggestions(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "# Root\n\n[install](docs/instal.md) [ok](docs/install.md)\n",
+		"docs/install.md": "# Install\n",
+	})
+	options := DefaultOptions(treeRoot)
+	options.RespectGitIgnore = false
+	checker, err := NewChecker(options)
+	assert.NoError(t, err)
+	result, err := checker.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{Path: "README.md", Kind: DeadLinkFinding, Message: "docs/instal.md (did you mean docs/install.md?)"},
+	}, result.Findings)
+
+	options.Suggestions = false
+	checker, err = NewChecker(options)
+	assert.NoError(t, err)
+	result, err = checker.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{{Path: "README.md", Kind: DeadLinkFinding, Message: "docs/instal.md"}}, result.Findings)
+}
+
+func TestNewCheckerValidatesOptions(t *testing.T) {
+	_, err := NewChecker(Options{TreeRoot: "relative", Extensions: []string{".md"}})
+	assert.Error(t, err)
+	_, err = NewChecker(Options{TreeRoot: "/tmp"})
+	assert.Error(t, err)
+	_, err = NewChecker(Options{TreeRoot: "/tmp", Extensions: []string{"md"}})
+	assert.Error(t, err)
+	_, err = NewChecker(Options{TreeRoot: "/tmp", Extensions: []string{".md"},
+		IndexRequirements: IndexRequirements{Patterns: []string{"["}}})
+	assert.Error(t, err)
+}
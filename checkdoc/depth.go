@@ -0,0 +1,26 @@
+package checkdoc
+
+// DepthViolation tells how deep a document sits below the root documents, when it is deeper than allowed.
+type DepthViolation struct {
+	Depth int      `json:"depth"` // Number of links to follow from a root document
+	Path  []string `json:"path"`  // The shortest path of documents reaching the document, starting with a root
+}
+
+// CheckDepth computes the click depth of each reported document, as the length of the shortest path
+// from any of the root documents, and records the ones deeper than maxDepth as TooDeep in their report.
+// Documents that can't be reached at all are left alone: they are orphans, or only linked from orphans.
+// Nothing is checked if maxDepth is 0 or less.
+func CheckDepth(treeRoot string, reports map[string]NodeReport, implicitIndexes []string, roots []string, maxDepth int) {
+	if maxDepth <= 0 {
+		return
+	}
+	paths := ShortestPaths(BuildGraph(treeRoot, reports, implicitIndexes), roots)
+	for path, report := range reports {
+		shortestPath, reachable := paths[path]
+		if !reachable || len(shortestPath)-1 <= maxDepth {
+			continue
+		}
+		report.TooDeep = &DepthViolation{Depth: len(shortestPath) - 1, Path: shortestPath}
+		reports[path] = report
+	}
+}
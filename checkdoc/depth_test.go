@@ -0,0 +1,42 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestCheckDepth(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":      "# Root\n\n[a](a/)\n",
+		"a/README.md":    "# A\n\n[b](b.md)\n",
+		"a/b.md":         "# B\n\n[c](c.md)\n",
+		"a/c.md":         "# C\n",
+		"start.md":       "# Start\n\n[c](a/c.md)\n",
+		"unreachable.md": "# Unreachable\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	CheckDepth(treeRoot, reports, []string{"README.md"}, []string{"README.md"}, 2)
+	assert.Nil(t, reports["a/b.md"].TooDeep)
+	assert.Nil(t, reports["unreachable.md"].TooDeep)
+	assert.Equal(t, &DepthViolation{Depth: 3, Path: []string{"README.md", "a/README.md", "a/b.md", "a/c.md"}},
+		reports["a/c.md"].TooDeep)
+	assert.False(t, ValidateReports(map[string]NodeReport{"a/c.md": reports["a/c.md"]}))
+
+	// A second root brings it closer
+	reports = BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	CheckDepth(treeRoot, reports, []string{"README.md"}, []string{"README.md", "start.md"}, 2)
+	for path, report := range reports {
+		assert.Nil(t, report.TooDeep, path)
+	}
+
+	// No limit
+	reports = BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	CheckDepth(treeRoot, reports, []string{"README.md"}, []string{"README.md"}, 0)
+	assert.Nil(t, reports["a/c.md"].TooDeep)
+}
@@ -0,0 +1,32 @@
+package checkdoc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// CheckDirectoryLinks looks for links to existing directories that contain none of the implicit index files,
+// and records them as DirectoryLinks in the corresponding report.
+// Such links are otherwise valid: this is meant for a strict mode where a link to a directory is expected
+// to lead to a document.
+func CheckDirectoryLinks(treeRoot string, reports map[string]NodeReport, implicitIndexes []string) {
+	for path, report := range reports {
+		var withoutIndex []string
+		for _, link := range report.Node.NormalizedLocalRelativeLinks {
+			if slices.Contains(withoutIndex, link) {
+				continue
+			}
+			info, err := os.Stat(filepath.Join(treeRoot, link))
+			if err != nil || !info.IsDir() {
+				// Dead links are reported on their own
+				continue
+			}
+			if len(implicitIndexFiles(treeRoot, implicitIndexes, link)) == 0 {
+				withoutIndex = append(withoutIndex, link)
+			}
+		}
+		report.DirectoryLinks = withoutIndex
+		reports[path] = report
+	}
+}
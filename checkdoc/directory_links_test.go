@@ -0,0 +1,29 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestCheckDirectoryLinks(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[docs](docs/) [bare](bare/) [again](./bare) [code](bare/main.go) " +
+			"[gone](gone/)\n",
+		"docs/README.md": "# Docs\n",
+		"bare/main.go":   "package main\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	assert.True(t, ValidateReports(map[string]NodeReport{"README.md": {Node: reports["README.md"].Node}}))
+
+	CheckDirectoryLinks(treeRoot, reports, []string{"README.md"})
+
+	assert.Equal(t, []string{"bare"}, reports["README.md"].DirectoryLinks)
+	assert.Empty(t, reports["docs/README.md"].DirectoryLinks)
+	assert.False(t, ValidateReports(map[string]NodeReport{"README.md": {Node: reports["README.md"].Node,
+		DirectoryLinks: reports["README.md"].DirectoryLinks}}))
+}
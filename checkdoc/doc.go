@@ -0,0 +1,3 @@
+// Package checkdoc checks the markdown documentation of a tree: it builds the link graph between documents,
+// and reports orphans, dead links and the findings of the other checks. See Checker to run them all at once.
+package checkdoc
@@ -0,0 +1,98 @@
+package checkdoc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ImplicitLink is a link that relies on implicit resolution to reach a document:
+// a link to a directory leading to its implicit index, or a link missing the document's extension.
+type ImplicitLink struct {
+	Link   string `json:"link"`   // The link, normalized relative to the root
+	Target string `json:"target"` // The document the link resolves to, relative to the root
+}
+
+// CheckExplicitLinks looks for links relying on implicit resolution, and records them as ImplicitLinks
+// in the corresponding report. Links to directories are resolved with implicitIndexes, like BuildReport does.
+// Links missing an extension are resolved by trying each of the passed document extensions: BuildReport
+// does not resolve them, so that they are reported as dead links as well.
+// This is meant for renderers other than GitHub, that don't resolve such links.
+func CheckExplicitLinks(treeRoot string, reports map[string]NodeReport, implicitIndexes []string, extensions []string) {
+	for path, report := range reports {
+		var implicitLinks []ImplicitLink
+		for _, link := range report.Node.NormalizedLocalRelativeLinks {
+			if slices.ContainsFunc(implicitLinks, func(implicit ImplicitLink) bool { return implicit.Link == link }) {
+				continue
+			}
+			if target, found := resolveImplicitLink(treeRoot, reports, implicitIndexes, extensions, link); found {
+				implicitLinks = append(implicitLinks, ImplicitLink{Link: link, Target: target})
+			}
+		}
+		report.ImplicitLinks = implicitLinks
+		reports[path] = report
+	}
+}
+
+// resolveImplicitLink returns the document a normalized link implicitly resolves to, if any.
+func resolveImplicitLink(
+	treeRoot string,
+	reports map[string]NodeReport,
+	implicitIndexes []string,
+	extensions []string,
+	link string,
+) (string, bool) {
+	if _, isDocument := reports[link]; isDocument {
+		return "", false
+	}
+	if indexes := implicitIndexFiles(treeRoot, implicitIndexes, link); len(indexes) > 0 {
+		// The first existing index is the one renderers show
+		return indexes[0], true
+	}
+	if filepath.Ext(link) != "" {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(treeRoot, link)); err == nil {
+		// Something without extension that exists is an explicit link to it
+		return "", false
+	}
+	for _, extension := range extensions {
+		if _, isDocument := reports[link+extension]; isDocument {
+			return link + extension, true
+		}
+	}
+	return "", false
+}
+
+// PlanExplicitLinkFixes returns the fixes rewriting the implicit links found by CheckExplicitLinks
+// to the document they resolve to, sorted by document.
+func PlanExplicitLinkFixes(treeRoot string, reports map[string]NodeReport) []LinkFix {
+	var fixes []LinkFix
+	for path, report := range reports {
+		targets := make(map[string]string)
+		for _, implicitLink := range report.ImplicitLinks {
+			targets[implicitLink.Link] = implicitLink.Target
+		}
+		fixes = append(fixes, planRewrites(treeRoot, path, report.Node, func(link string) (string, bool) {
+			target, found := targets[link]
+			return target, found
+		})...)
+	}
+	sortFixes(fixes)
+	return fixes
+}
+
+// MergeFixes merges two lists of fixes sorted by document, in a list sorted by document.
+// When both rewrite the same destination in the same document, the preferred fix is kept.
+func MergeFixes(preferred []LinkFix, others []LinkFix) []LinkFix {
+	merged := slices.Clone(preferred)
+	for _, fix := range others {
+		if !slices.ContainsFunc(preferred, func(p LinkFix) bool {
+			return p.DocumentPath == fix.DocumentPath && p.OldDestination == fix.OldDestination
+		}) {
+			merged = append(merged, fix)
+		}
+	}
+	sortFixes(merged)
+	return merged
+}
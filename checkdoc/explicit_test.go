@@ -0,0 +1,54 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestCheckExplicitLinks(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[docs](docs/) [install](docs/install) [again](./docs#top) [explicit](docs/README.md)\n" +
+			"[bare](bare/) [script](bin/run) [gone](gone)\n",
+		"docs/README.md":  "# Docs\n",
+		"docs/install.md": "# Install\n",
+		"bare/main.go":    "package main\n",
+		"bin/run":         "#!/bin/sh\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+
+	CheckExplicitLinks(treeRoot, reports, []string{"README.md"}, []string{".md"})
+
+	assert.Equal(t, []ImplicitLink{
+		{Link: "docs", Target: "docs/README.md"},
+		{Link: "docs/install", Target: "docs/install.md"},
+	}, reports["README.md"].ImplicitLinks)
+	assert.Empty(t, reports["docs/README.md"].ImplicitLinks)
+	assert.Equal(t, []string{"docs/install", "gone"}, reports["README.md"].DeadLinks,
+		"Links missing the extension should be dead links as well")
+
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "docs/README.md",
+			OldDestination: "docs/", NewDestination: "docs/README.md"},
+		{DocumentPath: "README.md", DeadLink: "docs/install", Target: "docs/install.md",
+			OldDestination: "docs/install", NewDestination: "docs/install.md"},
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "docs/README.md",
+			OldDestination: "./docs#top", NewDestination: "./docs/README.md#top"},
+	}, PlanExplicitLinkFixes(treeRoot, reports))
+}
+
+func TestMergeFixes(t *testing.T) {
+	preferred := []LinkFix{{DocumentPath: "b.md", OldDestination: "x", NewDestination: "x.md"}}
+	others := []LinkFix{
+		{DocumentPath: "a.md", OldDestination: "x", NewDestination: "y.md"},
+		{DocumentPath: "b.md", OldDestination: "x", NewDestination: "y.md"},
+	}
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "a.md", OldDestination: "x", NewDestination: "y.md"},
+		{DocumentPath: "b.md", OldDestination: "x", NewDestination: "x.md"},
+	}, MergeFixes(preferred, others))
+}
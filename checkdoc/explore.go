@@ -3,32 +3,36 @@ package checkdoc
 //revive:disable:flag-parameter
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/denormal/go-gitignore"
-	blackfriday "github.com/russross/blackfriday/v2"
 
 	"github.com/open-ch/checkdoc/markdown"
 )
 
-// parsedAST is a tuple of an absolute path to a markdown file, and its parsed abstract syntax tree (AST).
-type parsedAST struct {
-	AbsPath   string
-	ParsedAST *blackfriday.Node
+// parsedDocument is a tuple of an absolute path to a markdown file, its source and its parsed representation.
+type parsedDocument struct {
+	AbsPath  string
+	Source   []byte
+	Document markdown.Document
 }
 
 // RelativeAST is a tuple of a relative path to a markdown file, its parsed representation
 // as well as relative links (normalized to the root!) found in links
 type RelativeAST struct {
 	RelativePath string
-	ParsedAST    *blackfriday.Node
+	Document     markdown.Document
 	// All local links found in the markdown. "Absolute" links (starting with a '/') are relative to the root,
 	// relative links are relative to RelativePath.
 	// Relative links may contain things like '.' and '..'
-	LocalRelativeLinks []blackfriday.LinkData
+	LocalRelativeLinks []markdown.Link
 }
 
 // LinkGraphNode represents a markdown file, by its relative path from a root,
@@ -36,8 +40,16 @@ type RelativeAST struct {
 // Ie, there should not be any . or .. in any path anymore.
 type LinkGraphNode struct {
 	RelativePath                 string            // Path of the file from the root
-	ParsedAST                    *blackfriday.Node // The parsed AST from the file referred by this node
+	Document                     markdown.Document // The parsed document from the file referred by this node
 	NormalizedLocalRelativeLinks []string          // links to other files, relative from the root
+	NormalizedLocalImageLinks    []string          // images embedded in the file, relative from the root
+	Metadata                     map[string]any    // the document's front matter, nil if it has none
+}
+
+// MetadataString returns the value of the passed front matter field, if it is set and is a string.
+func (node LinkGraphNode) MetadataString(key string) (string, bool) {
+	value, ok := node.Metadata[key].(string)
+	return value, ok
 }
 
 // BuildLinkGraphNodes takes a path to a directory, the content of which will be explored recursively.
@@ -45,12 +57,29 @@ type LinkGraphNode struct {
 // both can be used together, ie, search for {README, CHANGELOG} and "*.md".
 // All matching files will have a corresponding node, but they may well have internal links that point to files
 // that do not have a corresponding node, or files that may not even exist.
+// Files are parsed with the blackfriday backend, see BuildLinkGraphNodesWithParser to use another one.
 // TODO deduplicate when/where relevant (if matches occur via basename and extension)
 func BuildLinkGraphNodes(
 	treeRoot string,
 	baseNames []string,
 	fileExtensions []string,
 	respectGitIgnore bool,
+) ([]LinkGraphNode, error) {
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	if err != nil {
+		return nil, err
+	}
+	return BuildLinkGraphNodesWithParser(treeRoot, baseNames, fileExtensions, respectGitIgnore, parser)
+}
+
+// BuildLinkGraphNodesWithParser works like BuildLinkGraphNodes, parsing markdown files with the passed parser.
+// It can't be cancelled: Checker.LinkGraph does the same with a context.
+func BuildLinkGraphNodesWithParser(
+	treeRoot string,
+	baseNames []string,
+	fileExtensions []string,
+	respectGitIgnore bool,
+	parser markdown.Parser,
 ) ([]LinkGraphNode, error) {
 	// Input validation
 	if len(baseNames) == 0 && len(fileExtensions) == 0 {
@@ -62,36 +91,65 @@ func BuildLinkGraphNodes(
 	}
 
 	// Get to work finding relevant files
-	results, err := findMatchingFiles(treeRoot, baseNames, fileExtensions)
+	ctx := context.Background()
+	results, err := findMatchingFiles(ctx, treeRoot, baseNames, fileExtensions)
 	if err != nil {
 		return nil, err
 	}
 
-	var filteredResults []string
-	if respectGitIgnore {
-		// Filter out anything that matches a gitignore (if required)
-		// Respect the gitignore
-		gitIgnore, err := gitignore.NewRepository(treeRoot)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build up a gitignore from a git repository. "+
-				"Is treeRoot pointing to a git repository? It was: %s - %s", treeRoot, err)
-		}
-		for _, path := range results {
-			// match is nil if the path does not match the gitignore
-			match := gitIgnore.Absolute(path, false)
-			if match == nil {
-				filteredResults = append(filteredResults, path)
-			}
+	filteredResults, err := filterGitIgnored(treeRoot, results, respectGitIgnore)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFilesAndBuildGraph(ctx, filteredResults, treeRoot, parser)
+}
+
+// filterGitIgnored removes the absolute paths matching the gitignore files of treeRoot, if required.
+func filterGitIgnored(treeRoot string, absPaths []string, respectGitIgnore bool) ([]string, error) {
+	if !respectGitIgnore {
+		return absPaths, nil
+	}
+	gitIgnore, err := newGitIgnore(treeRoot)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []string
+	for _, path := range absPaths {
+		// match is nil if the path does not match the gitignore
+		match := gitIgnore.Absolute(path, false)
+		if match == nil {
+			filtered = append(filtered, path)
 		}
-	} else {
-		filteredResults = results
 	}
+	return filtered, nil
+}
 
-	return parseFilesAndBuildGraph(filteredResults, treeRoot)
+// IsGitIgnored tells if the absolute path, a directory if isDir, matches the gitignore files of treeRoot.
+func IsGitIgnored(treeRoot string, absPath string, isDir bool) (bool, error) {
+	gitIgnore, err := newGitIgnore(treeRoot)
+	if err != nil {
+		return false, err
+	}
+	return gitIgnore.Absolute(absPath, isDir) != nil, nil
 }
 
-func parseFilesAndBuildGraph(absFilePaths []string, treeRoot string) ([]LinkGraphNode, error) {
-	parsedFiles, err := parseFiles(absFilePaths)
+func newGitIgnore(treeRoot string) (gitignore.GitIgnore, error) {
+	gitIgnore, err := gitignore.NewRepository(treeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build up a gitignore from a git repository. "+
+			"Is treeRoot pointing to a git repository? It was: %s - %s", treeRoot, err)
+	}
+	return gitIgnore, nil
+}
+
+func parseFilesAndBuildGraph(
+	ctx context.Context,
+	absFilePaths []string,
+	treeRoot string,
+	parser markdown.Parser,
+) ([]LinkGraphNode, error) {
+	parsedFiles, err := parseFiles(ctx, absFilePaths, parser)
 	if err != nil {
 		return nil, err
 	}
@@ -101,38 +159,66 @@ func parseFilesAndBuildGraph(absFilePaths []string, treeRoot string) ([]LinkGrap
 
 	var graphNodes []LinkGraphNode
 	for _, parsedFile := range parsedFiles {
-		filePathFromTreeRoot := strings.TrimPrefix(parsedFile.AbsPath, sanitizedRoot)
-		normalizedRelLinks, err :=
-			normalizeLinksToRoot(
-				sanitizedRoot,
-				filePathFromTreeRoot,
-				keepLinksAsStrings(
-					markdown.FilterLocalLinks(
-						markdown.ExtractAllLinks(parsedFile.ParsedAST)),
-					true,
-				),
-			)
-
+		node, err := buildGraphNode(sanitizedRoot, parsedFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to normalize relative links in %s from root %s:%s", normalizedRelLinks, treeRoot, err)
+			return nil, err
 		}
-
-		graphNodes = append(graphNodes,
-			LinkGraphNode{
-				RelativePath:                 filePathFromTreeRoot,
-				ParsedAST:                    parsedFile.ParsedAST,
-				NormalizedLocalRelativeLinks: normalizedRelLinks,
-			})
+		graphNodes = append(graphNodes, node)
 	}
 
 	return graphNodes, nil
 }
 
-func keepLinksAsStrings(linkDatas []blackfriday.LinkData, trimAnchors bool) []string {
+// buildGraphNode builds the node of a parsed file, normalizing its links to sanitizedRoot, which ends with a slash.
+func buildGraphNode(sanitizedRoot string, parsedFile *parsedDocument) (LinkGraphNode, error) {
+	treeRoot := strings.TrimSuffix(sanitizedRoot, "/")
+	filePathFromTreeRoot := strings.TrimPrefix(parsedFile.AbsPath, sanitizedRoot)
+	// Unused and duplicate definitions may not show up as links in the AST, but we still want to know if they are dead.
+	links := slices.Concat(
+		parsedFile.Document.Links,
+		definitionLinks(parsedFile.Document))
+	normalizedRelLinks, err :=
+		normalizeLinksToRoot(
+			sanitizedRoot,
+			filePathFromTreeRoot,
+			keepLinksAsStrings(
+				markdown.FilterLocalLinks(links),
+				true,
+			),
+		)
+
+	if err != nil {
+		return LinkGraphNode{}, fmt.Errorf("failed to normalize relative links in %s from root %s:%s", normalizedRelLinks, treeRoot, err)
+	}
+
+	normalizedImageLinks, err :=
+		normalizeLinksToRoot(
+			sanitizedRoot,
+			filePathFromTreeRoot,
+			keepLinksAsStrings(
+				markdown.FilterLocalLinks(parsedFile.Document.Images),
+				true,
+			),
+		)
+
+	if err != nil {
+		return LinkGraphNode{}, fmt.Errorf("failed to normalize image links in %s from root %s:%s", filePathFromTreeRoot, treeRoot, err)
+	}
+
+	return LinkGraphNode{
+		RelativePath:                 filePathFromTreeRoot,
+		Document:                     parsedFile.Document,
+		NormalizedLocalRelativeLinks: normalizedRelLinks,
+		NormalizedLocalImageLinks:    normalizedImageLinks,
+		Metadata:                     parsedFile.Document.FrontMatter,
+	}, nil
+}
+
+func keepLinksAsStrings(links []markdown.Link, trimAnchors bool) []string {
 	var toRet []string
-	for _, linkData := range linkDatas {
+	for _, link := range links {
 		// TODO validate existence of Anchor at destination?
-		var linkStr = string(linkData.Destination)
+		var linkStr = link.Destination
 
 		if trimAnchors {
 			linkStr = strings.Split(linkStr, "#")[0]
@@ -170,7 +256,9 @@ func normalizeLinksToRoot(treeRoot string, filePath string, relativeLinks []stri
 	return normalizedRelativePaths, nil
 }
 
-func findMatchingFiles(treeRoot string, baseNames []string, fileExtensions []string) ([]string, error) {
+// findMatchingFiles returns the absolute paths of the files below treeRoot having one of the passed base names
+// or extensions. It stops walking the tree once ctx is done.
+func findMatchingFiles(ctx context.Context, treeRoot string, baseNames []string, fileExtensions []string) ([]string, error) {
 	var collectedFiles []string
 
 	// This was refactored to avoid fs util but each call to searchByFileName
@@ -178,7 +266,7 @@ func findMatchingFiles(treeRoot string, baseNames []string, fileExtensions []str
 	// TODO reverse the logice so we do a single tree walk here and then check relevant files
 	// to avoid looping the tree multiple times.
 	for _, baseName := range baseNames {
-		results, err := searchByFileName(treeRoot, baseName)
+		results, err := searchByFileName(ctx, treeRoot, baseName)
 		if err != nil {
 			return nil, err
 		}
@@ -186,7 +274,7 @@ func findMatchingFiles(treeRoot string, baseNames []string, fileExtensions []str
 		collectedFiles = append(collectedFiles, results...)
 	}
 	for _, ext := range fileExtensions {
-		results, err := searchByExtension(treeRoot, ext)
+		results, err := searchByExtension(ctx, treeRoot, ext)
 		if err != nil {
 			return nil, err
 		}
@@ -198,7 +286,7 @@ func findMatchingFiles(treeRoot string, baseNames []string, fileExtensions []str
 // searchByFileName Given a path, returns all sub-paths to files that are named exactly like fileName
 // rootPath must be absolute
 // Note: migrated from fsutils library.
-func searchByFileName(rootPath string, baseName string) ([]string, error) {
+func searchByFileName(ctx context.Context, rootPath string, baseName string) ([]string, error) {
 	if !filepath.IsAbs(rootPath) {
 		return nil, fmt.Errorf("rootPath is not absolute: %s", rootPath)
 	}
@@ -206,13 +294,13 @@ func searchByFileName(rootPath string, baseName string) ([]string, error) {
 		return nil, fmt.Errorf("baseName cannot be empty")
 	}
 
-	return basenameGlob(rootPath, baseName)
+	return basenameGlob(ctx, rootPath, baseName)
 }
 
 // SearchByExtension Given a path, returns all sub-paths to files that have the specified extension 'ext'.
 // Note that 'ext' must include a dot.
 // Note: migrated from fsutils library.
-func searchByExtension(rootPath string, ext string) ([]string, error) {
+func searchByExtension(ctx context.Context, rootPath string, ext string) ([]string, error) {
 	if !filepath.IsAbs(rootPath) {
 		return nil, fmt.Errorf("rootPath is not absolute: %s", rootPath)
 	}
@@ -223,14 +311,17 @@ func searchByExtension(rootPath string, ext string) ([]string, error) {
 		return nil, fmt.Errorf("extension must start with a dot (.): %s", ext)
 	}
 
-	return extensionGlob(rootPath, ext)
+	return extensionGlob(ctx, rootPath, ext)
 }
 
 // filepath.Glob does not support things like '**/file'
 // Note: migrated from fsutils library.
-func basenameGlob(dir string, baseName string) ([]string, error) {
+func basenameGlob(ctx context.Context, dir string, baseName string) ([]string, error) {
 	var files []string
 	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if filepath.Base(path) == baseName {
 			files = append(files, path)
 		}
@@ -241,9 +332,12 @@ func basenameGlob(dir string, baseName string) ([]string, error) {
 }
 
 // Note: migrated from fsutils library.
-func extensionGlob(dir string, ext string) ([]string, error) {
+func extensionGlob(ctx context.Context, dir string, ext string) ([]string, error) {
 	var files []string
 	err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if filepath.Ext(path) == ext {
 			files = append(files, path)
 		}
@@ -253,19 +347,32 @@ func extensionGlob(dir string, ext string) ([]string, error) {
 	return files, err
 }
 
-// parseFiles parses the filePaths, expecting them all to point to markdown files.
-// It returns a map of the paths to their corresponding AST's.
-func parseFiles(mdFilePaths []string) ([]*parsedAST, error) {
-	var asts []*parsedAST
+// parseFiles parses the filePaths with the passed parser, expecting them all to point to markdown files.
+// It returns the parsed documents, in the same order as the paths, or the error of ctx once it is done.
+func parseFiles(ctx context.Context, mdFilePaths []string, parser markdown.Parser) ([]*parsedDocument, error) {
+	var docs []*parsedDocument
 	for _, mdFilePath := range mdFilePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if !filepath.IsAbs(mdFilePath) {
 			return nil, fmt.Errorf("will not parse a relative path: %s", mdFilePath)
 		}
-		ast, err := markdown.ParseFileToAst(mdFilePath)
-		if err != nil {
+		doc, source, err := markdown.ParseFile(parser, mdFilePath)
+		if err := warnInvalidFrontMatter(mdFilePath, err); err != nil {
 			return nil, fmt.Errorf("failed to parse markdow file %s: %s", mdFilePath, err)
 		}
-		asts = append(asts, &parsedAST{mdFilePath, ast})
+		docs = append(docs, &parsedDocument{mdFilePath, source, doc})
+	}
+	return docs, nil
+}
+
+// warnInvalidFrontMatter warns about the front matter of the passed file if err tells it does not parse:
+// the file was then parsed as markdown, front matter included. It returns any other error.
+func warnInvalidFrontMatter(absPath string, err error) error {
+	if errors.Is(err, markdown.ErrInvalidFrontMatter) {
+		slog.Warn("Could not parse front matter, parsing it as markdown instead", "file", absPath, "err", err)
+		return nil
 	}
-	return asts, nil
+	return err
 }
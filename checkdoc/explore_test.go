@@ -1,12 +1,15 @@
 package checkdoc
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/open-ch/checkdoc/internal/testtree"
+	"github.com/open-ch/checkdoc/markdown"
 	"github.com/open-ch/checkdoc/mockrepo"
 
 	"github.com/stretchr/testify/assert"
@@ -19,6 +22,13 @@ func getTestDir(t *testing.T) string {
 	return dir
 }
 
+func blackfridayParser(t *testing.T) markdown.Parser {
+	t.Helper()
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	assert.NoError(t, err)
+	return parser
+}
+
 func TestBuildLinkGraphNodesFailures(t *testing.T) {
 	nodes, err := BuildLinkGraphNodes("/abs/path", []string{}, []string{}, false)
 	assert.Nil(t, nodes, "Not expecting any returned value on failure.")
@@ -44,16 +54,16 @@ func TestBuildLinkGraphNodes(t *testing.T) {
 func TestFindRelevantFilesNotExisting(t *testing.T) {
 	testDir := getTestDir(t)
 
-	emptyFind, emptyErr := findMatchingFiles(testDir, []string{}, []string{})
+	emptyFind, emptyErr := findMatchingFiles(context.Background(), testDir, []string{}, []string{})
 	// Not that returning an error is done from the public method using this function.
 	assert.Empty(t, emptyFind, "Should not return anything when no params are passed")
 	assert.NoError(t, emptyErr, "Should not fail on empty arguments")
 
-	emptyFind2, err := findMatchingFiles(testDir, []string{"not-existing.md"}, []string{})
+	emptyFind2, err := findMatchingFiles(context.Background(), testDir, []string{"not-existing.md"}, []string{})
 	assert.Empty(t, emptyFind2, "Should not return anything on non existing basename and empty extension.")
 	assert.NoError(t, err, "Should not fail with valid arguments")
 
-	emptyFind3, err := findMatchingFiles(testDir, []string{}, []string{".yolo"})
+	emptyFind3, err := findMatchingFiles(context.Background(), testDir, []string{}, []string{".yolo"})
 	assert.Empty(t, emptyFind3, "Should not return anything on empty basename and non-existing extension")
 	assert.NoError(t, err, "Should not fail with valid arguments")
 }
@@ -61,12 +71,12 @@ func TestFindRelevantFilesNotExisting(t *testing.T) {
 func TestFindRelevantFilesByBasename(t *testing.T) {
 	testDir := getTestDir(t)
 
-	singleFind, err := findMatchingFiles(testDir, []string{"some-md-file.md"}, []string{})
+	singleFind, err := findMatchingFiles(context.Background(), testDir, []string{"some-md-file.md"}, []string{})
 	assert.Equal(t, 1, len(singleFind), "expected to find a single file.")
 	assert.NoError(t, err, "Should not fail with valid arguments")
 	assert.True(t, strings.HasSuffix(singleFind[0], "/some-md-file.md"))
 
-	tripleFind, err := findMatchingFiles(testDir, []string{"README.md"}, []string{})
+	tripleFind, err := findMatchingFiles(context.Background(), testDir, []string{"README.md"}, []string{})
 	assert.Equal(t, 3, len(tripleFind), "expected to find a single file.")
 	assert.NoError(t, err, "Should not fail with valid arguments")
 	assert.True(t, strings.HasSuffix(tripleFind[0], "/README.md"))
@@ -76,7 +86,7 @@ func TestFindRelevantFilesByBasename(t *testing.T) {
 
 func TestFindRelevantFilesByExtension(t *testing.T) {
 	testDir := getTestDir(t)
-	mdFinds, err := findMatchingFiles(testDir, []string{}, []string{".md"})
+	mdFinds, err := findMatchingFiles(context.Background(), testDir, []string{}, []string{".md"})
 
 	assert.NoError(t, err, "Should not fail with valid arguments")
 	assert.Equal(t, 6, len(mdFinds), "Expected to find all test markdown files.")
@@ -90,7 +100,7 @@ func TestFindRelevantFilesByExtension(t *testing.T) {
 
 func TestFindRelevantFilesByNameAndExtension(t *testing.T) {
 	testDir := getTestDir(t)
-	allFinds, err := findMatchingFiles(testDir, []string{"README", "CHANGELOG"}, []string{".md"})
+	allFinds, err := findMatchingFiles(context.Background(), testDir, []string{"README", "CHANGELOG"}, []string{".md"})
 
 	assert.NoError(t, err, "Should not fail with valid arguments")
 
@@ -108,7 +118,7 @@ func TestFindRelevantFilesByNameAndExtensionHasDuplicate(t *testing.T) {
 	testDir := getTestDir(t)
 	// We explicitely check we obtain duplicates: removing them should be done elsewehere.
 
-	withDupes, err := findMatchingFiles(testDir, []string{"CHANGELOG.md"}, []string{".md"})
+	withDupes, err := findMatchingFiles(context.Background(), testDir, []string{"CHANGELOG.md"}, []string{".md"})
 
 	assert.NoError(t, err, "Should not fail with valid arguments")
 
@@ -146,12 +156,12 @@ func TestParseFiles(t *testing.T) {
 	testFileA := filepath.Join(testDir, "some-md-file.md")
 	testFileB := filepath.Join(testDir, "sub-dir-a/README")
 
-	emptyParse, emptyError := parseFiles([]string{})
+	emptyParse, emptyError := parseFiles(context.Background(), []string{}, blackfridayParser(t))
 
 	assert.Empty(t, emptyParse)
 	assert.NoError(t, emptyError)
 
-	parsedFiles, err := parseFiles([]string{testFileA, testFileB})
+	parsedFiles, err := parseFiles(context.Background(), []string{testFileA, testFileB}, blackfridayParser(t))
 
 	assert.NoError(t, err, "Expected no parsing error")
 	assert.Equal(t, 2, len(parsedFiles), "expected one output for each input")
@@ -162,17 +172,17 @@ func TestSearchByFileName(t *testing.T) {
 	assert.NoError(t, err)
 	testDir := filepath.Join(workDir, "test-data")
 
-	_, notAbsErr := searchByFileName("relative/path", "README")
+	_, notAbsErr := searchByFileName(context.Background(), "relative/path", "README")
 	assert.NotNil(t, notAbsErr, "Expected an error if provided with a relative path")
 
-	_, emptyNameErr := searchByFileName(testDir, "")
+	_, emptyNameErr := searchByFileName(context.Background(), testDir, "")
 	assert.NotNil(t, emptyNameErr, "Expected an error if provided with an empty filename")
 
-	singleNoExtension, _ := searchByFileName(testDir, "README")
+	singleNoExtension, _ := searchByFileName(context.Background(), testDir, "README")
 	assert.Equal(t, 1, len(singleNoExtension), "Expected a single match, at the root of the test directory")
 	assert.True(t, strings.HasSuffix(singleNoExtension[0], "README"))
 
-	withExtension, _ := searchByFileName(testDir, "README.md-ext")
+	withExtension, _ := searchByFileName(context.Background(), testDir, "README.md-ext")
 	assert.Equal(t, 2, len(withExtension), "Expected two matches")
 }
 
@@ -181,15 +191,60 @@ func TestSearchByExtension(t *testing.T) {
 	assert.NoError(t, err)
 	testDir := filepath.Join(workDir, "test-data")
 
-	_, notAbsErr := searchByExtension("relative/path", ".md-ext")
+	_, notAbsErr := searchByExtension(context.Background(), "relative/path", ".md-ext")
 	assert.NotNil(t, notAbsErr, "Expected an error if provided with a relative path")
 
-	_, emptyNameErr := searchByExtension(testDir, "")
+	_, emptyNameErr := searchByExtension(context.Background(), testDir, "")
 	assert.NotNil(t, emptyNameErr, "Expected an error if provided with an empty extension")
 
-	_, noDot := searchByExtension(testDir, "md-ext")
+	_, noDot := searchByExtension(context.Background(), testDir, "md-ext")
 	assert.NotNil(t, noDot, "Expected an error if provided with an extension not starting with a dot.")
 
-	withExtension, _ := searchByExtension(testDir, ".md-ext")
+	withExtension, _ := searchByExtension(context.Background(), testDir, ".md-ext")
 	assert.Equal(t, 2, len(withExtension), "Expected a single match, at the root of the test directory")
 }
+
+func TestBuildLinkGraphNodesWithParser(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# GFM\n\n| Table | Links |\n|---|---|\n| [a](docs/a.md) | [b](docs/b.md) |\n\n" +
+			"```\n[not a link](docs/in-code.md)\n```\n",
+	})
+
+	parser, err := markdown.NewParser(markdown.GFMBackend)
+	assert.NoError(t, err)
+	nodes, err := BuildLinkGraphNodesWithParser(treeRoot, []string{}, []string{".md"}, false, parser)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nodes))
+	assert.Equal(t, []string{"docs/a.md", "docs/b.md"}, nodes[0].NormalizedLocalRelativeLinks)
+}
+
+func TestBuildLinkGraphNodesFrontMatter(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "---\ntitle: Home\nstatus: draft\nredirect: \"[old](old/README.md)\"\n---\n# Home\n\n[docs](docs/README.md)\n",
+	})
+
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nodes))
+	assert.Equal(t, []string{"docs/README.md"}, nodes[0].NormalizedLocalRelativeLinks)
+	assert.Equal(t, map[string]any{"title": "Home", "status": "draft", "redirect": "[old](old/README.md)"},
+		nodes[0].Metadata)
+
+	title, ok := nodes[0].MetadataString("title")
+	assert.True(t, ok)
+	assert.Equal(t, "Home", title)
+	_, ok = nodes[0].MetadataString("owner")
+	assert.False(t, ok)
+}
+
+func TestBuildLinkGraphNodesInvalidFrontMatter(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "---\ntitle: [unclosed\n---\n# Home\n\n[docs](docs/README.md)\n",
+	})
+
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err, "Front matter that does not parse should only be warned about")
+	assert.Equal(t, 1, len(nodes))
+	assert.Nil(t, nodes[0].Metadata)
+	assert.Equal(t, []string{"docs/README.md"}, nodes[0].NormalizedLocalRelativeLinks)
+}
@@ -0,0 +1,146 @@
+package checkdoc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FindingKind tells which check a finding comes from.
+type FindingKind string
+
+const (
+	// OrphanFinding is a document nothing links to
+	OrphanFinding FindingKind = "orphan"
+	// DeadLinkFinding is a link to something that does not exist
+	DeadLinkFinding FindingKind = "dead-link"
+	// DirectoryLinkFinding is a link to a directory without index
+	DirectoryLinkFinding FindingKind = "directory-without-index"
+	// ImplicitLinkFinding is a link relying on implicit resolution
+	ImplicitLinkFinding FindingKind = "implicit-link"
+	// LinkStyleFinding is a link not following the link style
+	LinkStyleFinding FindingKind = "link-style"
+	// BrokenImageFinding is an image that does not exist, or failed the content checks
+	BrokenImageFinding FindingKind = "broken-image"
+	// ReferenceFinding is an undefined, unused or duplicate reference
+	ReferenceFinding FindingKind = "reference"
+	// TooDeepFinding is a document too far away from the root documents
+	TooDeepFinding FindingKind = "too-deep"
+	// RequiredIndexFinding is a directory lacking a required index
+	RequiredIndexFinding FindingKind = "missing-required-index"
+)
+
+// Finding is a single issue reported by the checks, described as in the logs of ValidateReports.
+type Finding struct {
+	Path    string      `json:"path"`    // The document, or directory, the finding is about
+	Kind    FindingKind `json:"kind"`    // The check the finding comes from
+	Message string      `json:"message"` // What is wrong, and how to fix it if known
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Path, f.Message, f.Kind)
+}
+
+// ListFindings flattens the passed reports and the directories lacking a required index into a list of
+// distinct findings, sorted by path, kind and message.
+func ListFindings(reports map[string]NodeReport, withoutIndex []string) []Finding {
+	var findings []Finding
+	for _, finding := range listLocatedFindings(reports, withoutIndex) {
+		findings = append(findings, finding.Finding)
+	}
+	return findings
+}
+
+// locatedFinding is a finding along with what it is about in the document, to locate it in its source.
+// At most one of target, destination and line is set.
+type locatedFinding struct {
+	Finding
+	target      string // A link normalized relative to the root, found in the document through its destinations
+	destination string // A link destination, as written in the document
+	line        int    // A line of the document
+}
+
+// listLocatedFindings is ListFindings, keeping track of what the findings are about.
+func listLocatedFindings(reports map[string]NodeReport, withoutIndex []string) []locatedFinding {
+	var findings []locatedFinding
+	for path, report := range reports {
+		add := func(subject locatedFinding, kind FindingKind, format string, args ...any) {
+			subject.Finding = Finding{Path: path, Kind: kind, Message: fmt.Sprintf(format, args...)}
+			findings = append(findings, subject)
+		}
+		if report.IsOrphan {
+			add(locatedFinding{}, OrphanFinding, "nothing links to this document")
+		}
+		for _, deadLink := range report.DeadLinks {
+			add(locatedFinding{target: deadLink}, DeadLinkFinding, "%s%s",
+				deadLink, DidYouMean(report.Suggestions[deadLink]))
+		}
+		for _, directory := range report.DirectoryLinks {
+			add(locatedFinding{target: directory}, DirectoryLinkFinding, "%s", directory)
+		}
+		for _, implicitLink := range report.ImplicitLinks {
+			add(locatedFinding{target: implicitLink.Link}, ImplicitLinkFinding, "%s resolves to %s",
+				implicitLink.Link, implicitLink.Target)
+		}
+		for _, issue := range report.LinkStyleIssues {
+			subject := locatedFinding{destination: issue.Destination}
+			if issue.Suggestion != "" {
+				add(subject, LinkStyleFinding, "%s is %s, use %s instead", issue.Destination, issue.Reason, issue.Suggestion)
+			} else {
+				add(subject, LinkStyleFinding, "%s is %s", issue.Destination, issue.Reason)
+			}
+		}
+		for _, deadImage := range report.DeadImageLinks {
+			add(locatedFinding{target: deadImage}, BrokenImageFinding, "%s not found%s",
+				deadImage, DidYouMean(report.Suggestions[deadImage]))
+		}
+		for _, invalidImage := range report.InvalidImages {
+			add(locatedFinding{target: invalidImage.Path}, BrokenImageFinding, "%s %s",
+				invalidImage.Path, invalidImage.Reason)
+		}
+		for _, issue := range report.ReferenceIssues {
+			add(locatedFinding{line: issue.Line}, ReferenceFinding, "line %d: [%s] %s",
+				issue.Line, issue.Label, issue.Kind)
+		}
+		if report.TooDeep != nil {
+			add(locatedFinding{}, TooDeepFinding, "depth %d: %s",
+				report.TooDeep.Depth, strings.Join(report.TooDeep.Path, " -> "))
+		}
+	}
+	for _, directory := range withoutIndex {
+		findings = append(findings, locatedFinding{
+			Finding: Finding{Path: directory, Kind: RequiredIndexFinding, Message: "no index found"},
+		})
+	}
+	slices.SortFunc(findings, func(a, b locatedFinding) int {
+		return compareFindings(a.Finding, b.Finding)
+	})
+	// The same dead link may appear several times in a document
+	return slices.Compact(findings)
+}
+
+func compareFindings(a, b Finding) int {
+	if a.Path != b.Path {
+		return strings.Compare(a.Path, b.Path)
+	}
+	if a.Kind != b.Kind {
+		return strings.Compare(string(a.Kind), string(b.Kind))
+	}
+	return strings.Compare(a.Message, b.Message)
+}
+
+// DiffFindings compares two sorted lists of findings, returning the ones only found in current, and
+// the ones only found in previous, ie, that were fixed. Both are sorted.
+func DiffFindings(previous []Finding, current []Finding) (added []Finding, resolved []Finding) {
+	for _, finding := range current {
+		if _, found := slices.BinarySearchFunc(previous, finding, compareFindings); !found {
+			added = append(added, finding)
+		}
+	}
+	for _, finding := range previous {
+		if _, found := slices.BinarySearchFunc(current, finding, compareFindings); !found {
+			resolved = append(resolved, finding)
+		}
+	}
+	return added, resolved
+}
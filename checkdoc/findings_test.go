@@ -0,0 +1,45 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFindings(t *testing.T) {
+	reports := map[string]NodeReport{
+		"README.md": {},
+		"docs/install.md": {
+			IsOrphan:    true,
+			DeadLinks:   []string{"docs/setup.md", "docs/setup.md"},
+			Suggestions: map[string][]Suggestion{"docs/setup.md": {{Path: "docs/set-up.md"}}},
+			LinkStyleIssues: []LinkStyleIssue{
+				{Destination: "/docs/api.md", Reason: RootAbsolute, Suggestion: "api.md"},
+			},
+			ReferenceIssues: []ReferenceIssue{{Kind: UndefinedReference, Label: "faq", Line: 3}},
+		},
+	}
+
+	assert.Equal(t, []Finding{
+		{Path: "docs/install.md", Kind: DeadLinkFinding, Message: "docs/setup.md (did you mean docs/set-up.md?)"},
+		{Path: "docs/install.md", Kind: LinkStyleFinding, Message: "/docs/api.md is root-absolute, use api.md instead"},
+		{Path: "docs/install.md", Kind: OrphanFinding, Message: "nothing links to this document"},
+		{Path: "docs/install.md", Kind: ReferenceFinding, Message: "line 3: [faq] undefined-reference"},
+		{Path: "services", Kind: RequiredIndexFinding, Message: "no index found"},
+	}, ListFindings(reports, []string{"services"}))
+}
+
+func TestDiffFindings(t *testing.T) {
+	orphan := Finding{Path: "a.md", Kind: OrphanFinding, Message: "nothing links to this document"}
+	deadLink := Finding{Path: "a.md", Kind: DeadLinkFinding, Message: "b.md"}
+	tooDeep := Finding{Path: "c.md", Kind: TooDeepFinding, Message: "depth 4: README.md -> c.md"}
+
+	added, resolved := DiffFindings([]Finding{deadLink, orphan}, []Finding{orphan, tooDeep})
+	assert.Equal(t, []Finding{tooDeep}, added)
+	assert.Equal(t, []Finding{deadLink}, resolved)
+
+	added, resolved = DiffFindings([]Finding{orphan}, []Finding{orphan})
+	assert.Empty(t, added)
+	assert.Empty(t, resolved)
+	assert.Equal(t, "a.md: b.md (dead-link)", deadLink.String())
+}
@@ -0,0 +1,242 @@
+package checkdoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// LinkFix describes how a dead link is to be rewritten in a document.
+type LinkFix struct {
+	DocumentPath   string // Path of the document containing the link, relative to the root
+	DeadLink       string // The dead link, normalized relative to the root
+	Target         string // The new location of the link's target, relative to the root
+	OldDestination string // The link destination, as currently written in the document
+	NewDestination string // The link destination to write instead, pointing to Target
+}
+
+// PlanFixes looks for a new location for each dead link and image in the passed reports, using the passed relocators,
+// and returns the fixes to apply, sorted by document. Dead links for which no new location was found
+// are returned as well, by document.
+func PlanFixes(
+	treeRoot string,
+	reports map[string]NodeReport,
+	relocators []Relocator,
+) ([]LinkFix, map[string][]string) {
+	var fixes []LinkFix
+	unfixable := make(map[string][]string)
+
+	for path, report := range reports {
+		dead := make(map[string]bool)
+		for _, deadLink := range slices.Concat(report.DeadLinks, report.DeadImageLinks) {
+			dead[deadLink] = true
+		}
+		if len(dead) == 0 {
+			continue
+		}
+
+		fixed := make(map[string]bool)
+		fixes = append(fixes, planRewrites(treeRoot, path, report.Node, func(link string) (string, bool) {
+			if !dead[link] {
+				return "", false
+			}
+			target, found := Relocate(treeRoot, link, relocators)
+			fixed[link] = fixed[link] || found
+			return target, found
+		})...)
+
+		for deadLink := range dead {
+			if !fixed[deadLink] {
+				unfixable[path] = append(unfixable[path], deadLink)
+			}
+		}
+		slices.Sort(unfixable[path])
+	}
+
+	sortFixes(fixes)
+	return fixes, unfixable
+}
+
+// planRewrites returns the fixes to apply to the links of a document for which relocate returns a new target.
+// Each distinct destination is only rewritten once.
+func planRewrites(treeRoot string, path string, node LinkGraphNode, relocate Relocator) []LinkFix {
+	var fixes []LinkFix
+	forEachLocalLink(treeRoot, path, node, func(destination string, normalized string) {
+		target, found := relocate(normalized)
+		if !found {
+			return
+		}
+		newDestination := destination
+		if info, err := os.Stat(filepath.Join(treeRoot, target)); err == nil && !info.IsDir() {
+			// The link may have pointed to a directory, but the target is a file
+			newDestination = withoutTrailingSlash(newDestination)
+		}
+		fixes = append(fixes, LinkFix{
+			DocumentPath:   path,
+			DeadLink:       normalized,
+			Target:         target,
+			OldDestination: destination,
+			NewDestination: rewriteDestination(path, newDestination, target),
+		})
+	})
+	return fixes
+}
+
+// forEachLocalLink calls fn with each distinct local link and image destination of the node at path,
+// along with the link normalized relative to the root. All reference definitions are included.
+func forEachLocalLink(treeRoot string, path string, node LinkGraphNode, fn func(destination string, normalized string)) {
+	sanitizedRoot := strings.TrimSuffix(treeRoot, "/") + "/"
+	seen := make(map[string]bool)
+	links := slices.Concat(node.Document.Links, node.Document.Images,
+		definitionLinks(node.Document))
+	for _, link := range markdown.FilterLocalLinks(links) {
+		if seen[link.Destination] {
+			continue
+		}
+		seen[link.Destination] = true
+		normalized, err := normalizeLinksToRoot(sanitizedRoot, path, keepLinksAsStrings([]markdown.Link{link}, true))
+		if err != nil {
+			continue
+		}
+		fn(link.Destination, normalized[0])
+	}
+}
+
+// LinkDestination is a local link or image destination of a document.
+type LinkDestination struct {
+	Destination string // The destination, as written in the document
+	Target      string // The destination normalized relative to the root, without anchor
+}
+
+// LocalDestinations returns the distinct local link and image destinations of a node, including those of
+// unused reference definitions. Links to anchors of the node itself are left out.
+func LocalDestinations(treeRoot string, node LinkGraphNode) []LinkDestination {
+	var destinations []LinkDestination
+	forEachLocalLink(treeRoot, node.RelativePath, node, func(destination string, normalized string) {
+		destinations = append(destinations, LinkDestination{Destination: destination, Target: normalized})
+	})
+	return destinations
+}
+
+func sortFixes(fixes []LinkFix) {
+	slices.SortStableFunc(fixes, func(a, b LinkFix) int {
+		return strings.Compare(a.DocumentPath, b.DocumentPath)
+	})
+}
+
+// rewriteDestination builds a link destination pointing to target from the document at documentPath,
+// keeping the style of the original destination: root-absolute links stay root-absolute,
+// and anchors or trailing slashes are kept.
+func rewriteDestination(documentPath string, originalDestination string, target string) string {
+	path, anchor, hasAnchor := strings.Cut(originalDestination, "#")
+
+	var newDestination string
+	if strings.HasPrefix(path, "/") {
+		newDestination = "/" + target
+	} else {
+		relative, err := filepath.Rel(filepath.Dir(documentPath), target)
+		if err != nil {
+			// Both paths are relative to the same root, this can't happen.
+			relative = target
+		}
+		newDestination = filepath.ToSlash(relative)
+		if strings.HasPrefix(path, "./") && !strings.HasPrefix(newDestination, "../") {
+			newDestination = "./" + newDestination
+		}
+	}
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(newDestination, "/") {
+		newDestination += "/"
+	}
+	if hasAnchor {
+		newDestination += "#" + anchor
+	}
+	return newDestination
+}
+
+// ApplyFixes rewrites the link destinations of the passed fixes in a document's source.
+// Only the destinations are touched: the rest of the source is kept byte for byte.
+// Destinations are only replaced where they appear as such, ie, in inline links `[text](destination)`,
+// reference definitions `[label]: destination` and in href, src or srcset html attributes, outside of
+// front matter and of code, as told apart by the passed parser.
+// All fixes are applied in a single pass, so that a destination written by a fix is never rewritten by another one.
+// It returns the new source and the fixes that were applied at least once.
+func ApplyFixes(parser markdown.Parser, source []byte, fixes []LinkFix) ([]byte, []LinkFix) {
+	// Try longer destinations first, in case one is a prefix of another.
+	candidates := slices.Clone(fixes)
+	slices.SortStableFunc(candidates, func(a, b LinkFix) int {
+		return len(b.OldDestination) - len(a.OldDestination)
+	})
+
+	var result []byte
+	appliedDestinations := make(map[string]bool)
+	withoutCode := markdown.BlankCode(parser, source)
+	offset := 0
+	for offset < len(source) {
+		fix, found := matchFix(withoutCode, offset, candidates)
+		if !found {
+			result = append(result, source[offset])
+			offset++
+			continue
+		}
+		result = append(result, fix.NewDestination...)
+		appliedDestinations[fix.OldDestination] = true
+		offset += len(fix.OldDestination)
+	}
+
+	var applied []LinkFix
+	for _, fix := range fixes {
+		if appliedDestinations[fix.OldDestination] {
+			applied = append(applied, fix)
+		}
+	}
+	if len(applied) == 0 {
+		return source, nil
+	}
+	return result, applied
+}
+
+// matchFix returns the fix whose old destination starts at offset in the source, if any.
+func matchFix(source []byte, offset int, fixes []LinkFix) (LinkFix, bool) {
+	for _, fix := range fixes {
+		end := offset + len(fix.OldDestination)
+		if fix.OldDestination == "" || !bytes.HasPrefix(source[offset:], []byte(fix.OldDestination)) {
+			continue
+		}
+		if isDestinationStart(source[:offset]) && isDestinationEnd(source[end:]) {
+			return fix, true
+		}
+	}
+	return LinkFix{}, false
+}
+
+// destinationAttributeMatcher matches an html tag up to the start of the value of an attribute holding a link.
+var destinationAttributeMatcher = regexp.MustCompile(`(?i)^<[a-z][a-z0-9]*\s(?:[^>]*\s)?(?:href|src|srcset)\s*=\s*["']?$`)
+
+// srcsetCandidateMatcher matches an html tag up to the start of a srcset candidate following another one.
+var srcsetCandidateMatcher = regexp.MustCompile(`(?i)^<[a-z][a-z0-9]*\s(?:[^>]*\s)?srcset\s*=\s*["'][^"'>]*,\s*$`)
+
+// isDestinationStart checks that what comes before a link destination looks like the syntax
+// of an inline link, a reference definition, or an href, src or srcset html attribute,
+// where any candidate of a srcset may be a destination, not only the first one.
+func isDestinationStart(before []byte) bool {
+	if tagStart := bytes.LastIndexByte(before, '<'); tagStart >= 0 &&
+		(destinationAttributeMatcher.Match(before[tagStart:]) || srcsetCandidateMatcher.Match(before[tagStart:])) {
+		return true
+	}
+	before = bytes.TrimSuffix(before, []byte("<"))
+	trimmed := bytes.TrimRight(before, " \t")
+	return bytes.HasSuffix(trimmed, []byte("](")) || bytes.HasSuffix(trimmed, []byte("]:"))
+}
+
+// isDestinationEnd checks that what comes after a link destination ends it.
+func isDestinationEnd(after []byte) bool {
+	if len(after) == 0 {
+		return true
+	}
+	return strings.ContainsRune(" \t\r\n)>\"',", rune(after[0]))
+}
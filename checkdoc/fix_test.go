@@ -0,0 +1,108 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestRewriteDestination(t *testing.T) {
+	assert.Equal(t, "../guides/setup.md#setup", rewriteDestination("docs/README.md", "install.md#setup", "guides/setup.md"))
+	assert.Equal(t, "/guides/setup.md", rewriteDestination("docs/README.md", "/docs/install.md", "guides/setup.md"))
+	assert.Equal(t, "./setup.md", rewriteDestination("docs/README.md", "./install.md", "docs/setup.md"))
+	assert.Equal(t, "../guides/", rewriteDestination("docs/README.md", "./old/", "guides"))
+	assert.Equal(t, "guides/setup.md", rewriteDestination("README.md", "docs/install.md", "guides/setup.md"))
+}
+
+func TestApplyFixes(t *testing.T) {
+	source := []byte("# Doc\n\nSee [a](docs/a.md), [again](<docs/a.md> \"title\") but not docs/a.md or [docs/a.md].\n" +
+		"<a href=\"docs/a.md\">html</a> <img src='docs/a.md.png'>\n\n[ref]:   docs/a.md\n")
+	fixes := []LinkFix{
+		{DocumentPath: "README.md", OldDestination: "docs/a.md", NewDestination: "guides/a.md"},
+		{DocumentPath: "README.md", OldDestination: "not/there.md", NewDestination: "here.md"},
+	}
+
+	fixed, applied := ApplyFixes(blackfridayParser(t), source, fixes)
+
+	assert.Equal(t, "# Doc\n\nSee [a](guides/a.md), [again](<guides/a.md> \"title\") but not docs/a.md or [docs/a.md].\n"+
+		"<a href=\"guides/a.md\">html</a> <img src='docs/a.md.png'>\n\n[ref]:   guides/a.md\n", string(fixed))
+	assert.Equal(t, fixes[:1], applied)
+}
+
+func TestApplyFixesChained(t *testing.T) {
+	source := []byte("[a](docs/a.md) [b](docs/b.md) [c](<docs/c.md>)\n")
+	fixes := []LinkFix{
+		{DocumentPath: "README.md", OldDestination: "docs/a.md", NewDestination: "docs/b.md"},
+		{DocumentPath: "README.md", OldDestination: "docs/b.md", NewDestination: "docs/c.md"},
+		{DocumentPath: "README.md", OldDestination: "docs/c.md", NewDestination: "docs/a.md"},
+	}
+
+	fixed, applied := ApplyFixes(blackfridayParser(t), source, fixes)
+
+	assert.Equal(t, "[a](docs/b.md) [b](docs/c.md) [c](<docs/a.md>)\n", string(fixed),
+		"A destination written by a fix should not be rewritten by another one")
+	assert.Equal(t, fixes, applied)
+}
+
+func TestApplyFixesSkipsCode(t *testing.T) {
+	source := []byte("See [a](docs/a.md) or `[a](docs/a.md)`.\n\n```sh\nexport PAGE=docs/a.md\n```\n\n" +
+		"    <a href=\"docs/a.md\">indented</a>\n\n<div data-page=docs/a.md><img src=docs/a.md></div>\n")
+	fixes := []LinkFix{{DocumentPath: "README.md", OldDestination: "docs/a.md", NewDestination: "guides/a.md"}}
+
+	fixed, applied := ApplyFixes(blackfridayParser(t), source, fixes)
+
+	assert.Equal(t, "See [a](guides/a.md) or `[a](docs/a.md)`.\n\n```sh\nexport PAGE=docs/a.md\n```\n\n"+
+		"    <a href=\"docs/a.md\">indented</a>\n\n<div data-page=docs/a.md><img src=guides/a.md></div>\n", string(fixed))
+	assert.Equal(t, fixes, applied)
+}
+
+func TestApplyFixesSrcsetAndFrontMatter(t *testing.T) {
+	source := []byte("---\nrelated: '<a href=\"docs/a.md\">'\n---\n\n# Doc\n\n" +
+		"<img srcset=\"docs/a.md 1x, docs/a.md 2x\">\n\n[ref]:docs/a.md\n")
+	fixes := []LinkFix{{DocumentPath: "README.md", OldDestination: "docs/a.md", NewDestination: "guides/a.md"}}
+
+	fixed, applied := ApplyFixes(blackfridayParser(t), source, fixes)
+
+	assert.Equal(t, "---\nrelated: '<a href=\"docs/a.md\">'\n---\n\n# Doc\n\n"+
+		"<img srcset=\"guides/a.md 1x, guides/a.md 2x\">\n\n[ref]:guides/a.md\n", string(fixed),
+		"Front matter should be left untouched")
+	assert.Equal(t, fixes, applied)
+}
+
+func TestPlanFixes(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[install](docs/install.md#setup) [gone](docs/gone.md) ![img](/img/arch.png)\n" +
+			"[twice](docs/twice.md)\n",
+		"guides/install.md": "# Install\n",
+		"assets/arch.png":   "png",
+		"a/twice.md":        "# Twice\n",
+		"b/twice.md":        "# Twice\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+
+	relocator, err := NewBasenameRelocator(treeRoot)
+	assert.NoError(t, err)
+	fixes, unfixable := PlanFixes(treeRoot, reports, []Relocator{relocator})
+
+	assert.Equal(t, []LinkFix{
+		{
+			DocumentPath:   "README.md",
+			DeadLink:       "docs/install.md",
+			Target:         "guides/install.md",
+			OldDestination: "docs/install.md#setup",
+			NewDestination: "guides/install.md#setup",
+		},
+		{
+			DocumentPath:   "README.md",
+			DeadLink:       "img/arch.png",
+			Target:         "assets/arch.png",
+			OldDestination: "/img/arch.png",
+			NewDestination: "/assets/arch.png",
+		},
+	}, fixes)
+	assert.Equal(t, map[string][]string{"README.md": {"docs/gone.md", "docs/twice.md"}}, unfixable)
+}
@@ -0,0 +1,247 @@
+package checkdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Graph is the link graph between documents, along with the findings of each document.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"` // Sorted by path
+	Edges []GraphEdge `json:"edges"` // Sorted by origin, then destination
+}
+
+// GraphNode is a document in the link graph.
+type GraphNode struct {
+	Path      string `json:"path"`      // Path of the document, relative to the root
+	Directory string `json:"directory"` // Directory containing the document, "." for the root
+	Orphan    bool   `json:"orphan"`    // Nothing links to the document
+	DeadLinks int    `json:"deadLinks"` // How many dead links and images the document contains
+}
+
+// GraphEdge is a link from a document to another one.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BuildGraph builds the link graph between the documents of the passed reports.
+// Links to a directory lead to its implicit index files. Links to anything else than a document are left out.
+func BuildGraph(treeRoot string, reports map[string]NodeReport, implicitIndexes []string) Graph {
+	graph := Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	for path, report := range reports {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Path:      path,
+			Directory: filepath.Dir(path),
+			Orphan:    report.IsOrphan,
+			DeadLinks: len(report.DeadLinks) + len(report.DeadImageLinks),
+		})
+
+		targets := make(map[string]bool)
+		for _, link := range report.Node.NormalizedLocalRelativeLinks {
+			targets[link] = true
+			for _, index := range implicitIndexFiles(treeRoot, implicitIndexes, link) {
+				targets[index] = true
+			}
+		}
+		for target := range targets {
+			if _, isDocument := reports[target]; isDocument && target != path {
+				graph.Edges = append(graph.Edges, GraphEdge{From: path, To: target})
+			}
+		}
+	}
+
+	slices.SortFunc(graph.Nodes, func(a, b GraphNode) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	slices.SortFunc(graph.Edges, func(a, b GraphEdge) int {
+		if a.From != b.From {
+			return strings.Compare(a.From, b.From)
+		}
+		return strings.Compare(a.To, b.To)
+	})
+	return graph
+}
+
+// ShortestPaths returns, for each document reachable from the root documents, the shortest path of documents
+// leading to it, starting with a root. Ties are broken by the order of the roots, then by path.
+// Roots that are not documents of the graph are ignored.
+func ShortestPaths(graph Graph, roots []string) map[string][]string {
+	successors := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		// Edges are sorted, so are the successors.
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+	documents := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		documents[node.Path] = true
+	}
+
+	paths := make(map[string][]string)
+	var queue []string
+	for _, root := range roots {
+		if _, seen := paths[root]; documents[root] && !seen {
+			paths[root] = []string{root}
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range successors[current] {
+			if _, seen := paths[next]; seen {
+				continue
+			}
+			paths[next] = append(slices.Clone(paths[current]), next)
+			queue = append(queue, next)
+		}
+	}
+	return paths
+}
+
+// ResolveImplicitIndex returns the implicit index a normalized link to a directory leads to,
+// or the link itself if it does not point to a directory with an index.
+func ResolveImplicitIndex(treeRoot string, implicitIndexes []string, link string) string {
+	if indexes := implicitIndexFiles(treeRoot, implicitIndexes, link); len(indexes) > 0 {
+		// The first existing index is the one renderers show
+		return indexes[0]
+	}
+	return link
+}
+
+// implicitIndexFiles returns the existing index files of the passed directory, relative to the root.
+// It returns nothing if path is not a directory.
+func implicitIndexFiles(treeRoot string, implicitIndexes []string, path string) []string {
+	info, err := os.Stat(filepath.Join(treeRoot, path))
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	var indexes []string
+	for _, indexFile := range implicitIndexes {
+		relativeIndexPath := filepath.Join(path, indexFile)
+		indexInfo, err := os.Stat(filepath.Join(treeRoot, relativeIndexPath))
+		if err == nil && !indexInfo.IsDir() {
+			indexes = append(indexes, relativeIndexPath)
+		}
+	}
+	return indexes
+}
+
+// Colours of the documents with findings. Dead links take precedence over being an orphan.
+const (
+	orphanColour   = "#ffd8a8"
+	deadLinkColour = "#ffa8a8"
+)
+
+// WriteDOT writes the graph in the Graphviz DOT language, with a cluster per directory.
+func WriteDOT(w io.Writer, graph Graph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph checkdoc {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=filled, fillcolor=white];\n")
+	for i, directory := range graphDirectories(graph) {
+		indent := "  "
+		if directory != "." {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d {\n    label=%q;\n", i, directory)
+			indent = "    "
+		}
+		for _, node := range graph.Nodes {
+			if node.Directory != directory {
+				continue
+			}
+			fmt.Fprintf(&sb, "%s%q [label=%q", indent, node.Path, filepath.Base(node.Path))
+			if colour := nodeColour(node); colour != "" {
+				fmt.Fprintf(&sb, ", fillcolor=%q", colour)
+			}
+			sb.WriteString("];\n")
+		}
+		if directory != "." {
+			sb.WriteString("  }\n")
+		}
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q;\n", edge.From, edge.To)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, with a subgraph per directory.
+func WriteMermaid(w io.Writer, graph Graph) error {
+	// Paths are not valid Mermaid identifiers: nodes are identified by their index instead.
+	ids := make(map[string]string)
+	for i, node := range graph.Nodes {
+		ids[node.Path] = fmt.Sprintf("n%d", i)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, directory := range graphDirectories(graph) {
+		indent := "  "
+		if directory != "." {
+			fmt.Fprintf(&sb, "  subgraph d%d[\"%s\"]\n", i, mermaidText(directory))
+			indent = "    "
+		}
+		for _, node := range graph.Nodes {
+			if node.Directory == directory {
+				fmt.Fprintf(&sb, "%s%s[\"%s\"]\n", indent, ids[node.Path], mermaidText(filepath.Base(node.Path)))
+			}
+		}
+		if directory != "." {
+			sb.WriteString("  end\n")
+		}
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+	}
+	fmt.Fprintf(&sb, "  classDef orphan fill:%s\n", orphanColour)
+	fmt.Fprintf(&sb, "  classDef deadLinks fill:%s\n", deadLinkColour)
+	for _, node := range graph.Nodes {
+		switch nodeColour(node) {
+		case deadLinkColour:
+			fmt.Fprintf(&sb, "  class %s deadLinks\n", ids[node.Path])
+		case orphanColour:
+			fmt.Fprintf(&sb, "  class %s orphan\n", ids[node.Path])
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSONGraph writes the graph as JSON.
+func WriteJSONGraph(w io.Writer, graph Graph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(graph)
+}
+
+// graphDirectories returns the sorted directories containing the nodes of the graph.
+func graphDirectories(graph Graph) []string {
+	var directories []string
+	for _, node := range graph.Nodes {
+		directories = append(directories, node.Directory)
+	}
+	slices.Sort(directories)
+	return slices.Compact(directories)
+}
+
+func nodeColour(node GraphNode) string {
+	if node.DeadLinks > 0 {
+		return deadLinkColour
+	}
+	if node.Orphan {
+		return orphanColour
+	}
+	return ""
+}
+
+// mermaidText escapes the double quotes of a Mermaid label.
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
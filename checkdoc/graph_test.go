@@ -0,0 +1,119 @@
+package checkdoc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func buildTestGraph(t *testing.T) Graph {
+	t.Helper()
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "# Root\n\n[docs](docs/) [install](docs/install.md) [code](main.go)\n",
+		"main.go":         "package main\n",
+		"docs/README.md":  "# Docs\n\n[install](install.md) [self](README.md) [gone](gone.md)\n",
+		"docs/install.md": "# Install\n\n[root](../README.md)\n",
+		"docs/orphan.md":  "# Orphan\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	return BuildGraph(treeRoot, BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"}), []string{"README.md"})
+}
+
+func TestBuildGraph(t *testing.T) {
+	graph := buildTestGraph(t)
+
+	assert.Equal(t, []GraphNode{
+		{Path: "README.md", Directory: "."},
+		{Path: "docs/README.md", Directory: "docs", DeadLinks: 1},
+		{Path: "docs/install.md", Directory: "docs"},
+		{Path: "docs/orphan.md", Directory: "docs", Orphan: true},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "README.md", To: "docs/README.md"},
+		{From: "README.md", To: "docs/install.md"},
+		{From: "docs/README.md", To: "docs/install.md"},
+		{From: "docs/install.md", To: "README.md"},
+	}, graph.Edges)
+}
+
+func TestWriteDOT(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteDOT(&out, buildTestGraph(t)))
+	assert.Equal(t, `digraph checkdoc {
+  rankdir=LR;
+  node [shape=box, style=filled, fillcolor=white];
+  "README.md" [label="README.md"];
+  subgraph cluster_1 {
+    label="docs";
+    "docs/README.md" [label="README.md", fillcolor="#ffa8a8"];
+    "docs/install.md" [label="install.md"];
+    "docs/orphan.md" [label="orphan.md", fillcolor="#ffd8a8"];
+  }
+  "README.md" -> "docs/README.md";
+  "README.md" -> "docs/install.md";
+  "docs/README.md" -> "docs/install.md";
+  "docs/install.md" -> "README.md";
+}
+`, out.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteMermaid(&out, buildTestGraph(t)))
+	assert.Equal(t, `flowchart LR
+  n0["README.md"]
+  subgraph d1["docs"]
+    n1["README.md"]
+    n2["install.md"]
+    n3["orphan.md"]
+  end
+  n0 --> n1
+  n0 --> n2
+  n1 --> n2
+  n2 --> n0
+  classDef orphan fill:#ffd8a8
+  classDef deadLinks fill:#ffa8a8
+  class n1 deadLinks
+  class n3 orphan
+`, out.String())
+}
+
+func TestWriteJSONGraph(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteJSONGraph(&out, Graph{
+		Nodes: []GraphNode{{Path: "README.md", Directory: "."}},
+		Edges: []GraphEdge{},
+	}))
+	assert.JSONEq(t, `{
+		"nodes": [{"path": "README.md", "directory": ".", "orphan": false, "deadLinks": 0}],
+		"edges": []
+	}`, out.String())
+}
+
+func TestShortestPaths(t *testing.T) {
+	graph := Graph{
+		Nodes: []GraphNode{{Path: "README.md"}, {Path: "a.md"}, {Path: "b.md"}, {Path: "c.md"}, {Path: "lost.md"}},
+		Edges: []GraphEdge{
+			{From: "README.md", To: "a.md"},
+			{From: "README.md", To: "b.md"},
+			{From: "a.md", To: "c.md"},
+			{From: "b.md", To: "c.md"},
+			{From: "lost.md", To: "a.md"},
+		},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"README.md": {"README.md"},
+		"a.md":      {"README.md", "a.md"},
+		"b.md":      {"README.md", "b.md"},
+		"c.md":      {"README.md", "a.md", "c.md"},
+	}, ShortestPaths(graph, []string{"README.md", "missing.md"}))
+	assert.Equal(t, map[string][]string{
+		"b.md": {"b.md"},
+		"c.md": {"b.md", "c.md"},
+	}, ShortestPaths(graph, []string{"b.md"}))
+}
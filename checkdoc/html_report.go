@@ -0,0 +1,173 @@
+package checkdoc
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// How many lines of the source are shown around the lines findings are located at
+const snippetContext = 2
+
+//go:embed html_report.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// htmlReportData is what the HTML report template renders.
+type htmlReportData struct {
+	Valid       bool
+	Documents   int
+	Findings    int
+	Counts      []htmlCount
+	ByDirectory []htmlGroup
+	ByKind      []htmlGroup
+	Graph       Graph
+	Colours     map[string]string
+}
+
+// htmlCount is the number of findings of a kind.
+type htmlCount struct {
+	Kind  FindingKind
+	Count int
+}
+
+// htmlGroup is a set of findings sharing a directory or a kind.
+type htmlGroup struct {
+	Name     string
+	Findings []htmlFinding
+}
+
+// htmlFinding is a finding along with the parts of the document's source it was located at, if any.
+type htmlFinding struct {
+	Finding
+	Snippets []htmlSnippet
+}
+
+type htmlSnippet struct {
+	Lines []htmlLine
+}
+
+type htmlLine struct {
+	Number      int
+	Text        string
+	Highlighted bool
+}
+
+// WriteHTMLReport writes the findings of the passed reports and the directories lacking a required index
+// as a self-contained HTML page: summary counts, the findings grouped by directory and by kind with
+// the source lines around the links at fault, and the link graph between documents.
+func WriteHTMLReport(
+	w io.Writer,
+	treeRoot string,
+	reports map[string]NodeReport,
+	withoutIndex []string,
+	implicitIndexes []string,
+	parser markdown.Parser,
+	valid bool,
+) error {
+	data := htmlReportData{
+		Valid:     valid,
+		Documents: len(reports),
+		Graph:     BuildGraph(treeRoot, reports, implicitIndexes),
+		Colours:   map[string]string{"orphan": orphanColour, "deadLinks": deadLinkColour},
+	}
+
+	sources := make(map[string][]byte)
+	byDirectory := make(map[string][]htmlFinding)
+	byKind := make(map[string][]htmlFinding)
+	for _, located := range listLocatedFindings(reports, withoutIndex) {
+		finding := htmlFinding{Finding: located.Finding}
+		if report, isDocument := reports[located.Path]; isDocument {
+			source, read := sources[located.Path]
+			if !read {
+				var err error
+				if source, err = os.ReadFile(filepath.Join(treeRoot, located.Path)); err != nil {
+					return fmt.Errorf("could not read %s: %w", located.Path, err)
+				}
+				sources[located.Path] = source
+			}
+			finding.Snippets = snippets(source, findingLines(treeRoot, parser, report, source, located))
+		}
+
+		directory := filepath.Dir(located.Path)
+		if located.Kind == RequiredIndexFinding {
+			directory = located.Path
+		}
+		byDirectory[directory] = append(byDirectory[directory], finding)
+		byKind[string(located.Kind)] = append(byKind[string(located.Kind)], finding)
+		data.Findings++
+	}
+	for _, directory := range sortedKeys(byDirectory) {
+		data.ByDirectory = append(data.ByDirectory, htmlGroup{Name: directory, Findings: byDirectory[directory]})
+	}
+	for _, kind := range sortedKeys(byKind) {
+		data.ByKind = append(data.ByKind, htmlGroup{Name: kind, Findings: byKind[kind]})
+		data.Counts = append(data.Counts, htmlCount{Kind: FindingKind(kind), Count: len(byKind[kind])})
+	}
+
+	return htmlReport.Execute(w, data)
+}
+
+// findingLines returns the sorted lines of the document's source a finding is located at.
+func findingLines(
+	treeRoot string,
+	parser markdown.Parser,
+	report NodeReport,
+	source []byte,
+	finding locatedFinding,
+) []int {
+	var lines []int
+	switch {
+	case finding.line > 0:
+		lines = []int{finding.line}
+	case finding.destination != "":
+		lines = destinationLines(parser, source, finding.destination)
+	case finding.target != "":
+		for _, link := range LocalDestinations(treeRoot, report.Node) {
+			if link.Target == finding.target {
+				lines = append(lines, destinationLines(parser, source, link.Destination)...)
+			}
+		}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+// snippets returns the lines of source around the passed sorted lines, which are highlighted.
+// Snippets whose context overlaps are merged.
+func snippets(source []byte, lines []int) []htmlSnippet {
+	if len(lines) == 0 {
+		return nil
+	}
+	sourceLines := strings.Split(strings.TrimSuffix(string(source), "\n"), "\n")
+	var snippets []htmlSnippet
+	last := 0 // The last line of the previous snippet
+	for _, line := range lines {
+		if line > len(sourceLines) {
+			continue
+		}
+		first := max(line-snippetContext, last+1, 1)
+		if len(snippets) == 0 || first > last+1 {
+			snippets = append(snippets, htmlSnippet{})
+		}
+		current := &snippets[len(snippets)-1]
+		for number := first; number <= min(line+snippetContext, len(sourceLines)); number++ {
+			current.Lines = append(current.Lines, htmlLine{Number: number, Text: sourceLines[number-1]})
+			last = number
+		}
+		for i := range current.Lines {
+			if current.Lines[i].Number == line {
+				current.Lines[i].Highlighted = true
+			}
+		}
+	}
+	return snippets
+}
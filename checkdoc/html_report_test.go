@@ -0,0 +1,50 @@
+package checkdoc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":     "# Root\n\n[guide](docs/guide.md)\n",
+		"docs/guide.md": "# Guide\n\n1\n2\n3\n[gone](../gone.md) <script>\n4\n5\n6\n",
+		"docs/faq.md":   "# FAQ\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteHTMLReport(&out, treeRoot, reports, []string{"services/db"}, []string{"README.md"},
+		blackfridayParser(t), false))
+	html := out.String()
+
+	assert.Contains(t, html, `<span class="status invalid">failed</span>`)
+	assert.Contains(t, html, `<div class="count"><strong>3</strong>findings</div>`)
+	assert.Contains(t, html, `<div class="count"><strong>1</strong>dead-link</div>`)
+	assert.Contains(t, html, `<summary>docs (<span class="group-count">2</span>)</summary>`)
+	assert.Contains(t, html, `<summary>services/db (<span class="group-count">1</span>)</summary>`)
+	assert.Contains(t, html, `<div class="highlighted"><span class="number">6</span>[gone](../gone.md) &lt;script&gt;</div>`,
+		"The source around the dead link is shown, escaped")
+	assert.Contains(t, html, `{"from":"README.md","to":"docs/guide.md"}`)
+}
+
+func TestSnippets(t *testing.T) {
+	source := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+
+	assert.Nil(t, snippets(source, nil))
+	assert.Equal(t, []htmlSnippet{
+		{Lines: []htmlLine{{1, "1", false}, {2, "2", true}, {3, "3", false}, {4, "4", false}, {5, "5", true},
+			{6, "6", false}, {7, "7", false}}},
+	}, snippets(source, []int{2, 5}), "Overlapping snippets are merged")
+	assert.Equal(t, []htmlSnippet{
+		{Lines: []htmlLine{{1, "1", true}, {2, "2", false}, {3, "3", false}, {4, "4", false}, {5, "5", true},
+			{6, "6", false}, {7, "7", false}}},
+		{Lines: []htmlLine{{9, "9", false}, {10, "10", false}, {11, "11", true}, {12, "12", false}}},
+	}, snippets(source, []int{1, 5, 11, 13}))
+}
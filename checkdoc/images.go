@@ -0,0 +1,135 @@
+package checkdoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// How many bytes we look at to decide if a file is an image. This matches what http.DetectContentType considers.
+const sniffLength = 512
+
+// How many bytes of a text file we read, at most, looking for the root element of an SVG.
+// What comes before it, like an XML declaration, a doctype or a licence comment, is usually much shorter.
+const maxSVGPrologLength = 64 * 1024
+
+// InvalidImage is a local image that exists, but did not pass the image checks.
+type InvalidImage struct {
+	Path   string `json:"path"`   // Path to the image, relative from the root
+	Reason string `json:"reason"` // Why the image was considered invalid
+}
+
+// ImageChecks holds the checks to run against the content of local images.
+type ImageChecks struct {
+	VerifyContent bool  // Check that the target really is an image, by looking at its first bytes
+	MaxSize       int64 // Maximum size of an image in bytes. No limit if 0 or less.
+}
+
+// Enabled tells if any check is to be run at all.
+func (c ImageChecks) Enabled() bool {
+	return c.VerifyContent || c.MaxSize > 0
+}
+
+// CheckImages runs the passed checks against all existing images embedded in the reported nodes,
+// and records the ones that fail them as InvalidImages in the corresponding report.
+// Images that do not exist are skipped, as they are already reported as dead.
+func CheckImages(treeRoot string, reports map[string]NodeReport, checks ImageChecks) error {
+	if !checks.Enabled() {
+		return nil
+	}
+	for path, report := range reports {
+		dead := make(map[string]bool)
+		for _, deadImage := range report.DeadImageLinks {
+			dead[deadImage] = true
+		}
+
+		var invalidImages []InvalidImage
+		for _, image := range report.Node.NormalizedLocalImageLinks {
+			if dead[image] {
+				continue
+			}
+			reason, err := checkImage(filepath.Join(treeRoot, image), checks)
+			if err != nil {
+				return fmt.Errorf("failed to check image %s linked from %s: %w", image, path, err)
+			}
+			if reason != "" {
+				invalidImages = append(invalidImages, InvalidImage{Path: image, Reason: reason})
+			}
+		}
+		report.InvalidImages = invalidImages
+		reports[path] = report
+	}
+	return nil
+}
+
+// checkImage returns the reason why the image at absPath does not pass the checks,
+// or an empty string if it does.
+func checkImage(absPath string, checks ImageChecks) (string, error) {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "not a regular file", nil
+	}
+	if checks.MaxSize > 0 && info.Size() > checks.MaxSize {
+		return fmt.Sprintf("%d bytes exceeds the maximum of %d bytes", info.Size(), checks.MaxSize), nil
+	}
+	if !checks.VerifyContent {
+		return "", nil
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if !looksLikeImage(header[:n], f) {
+		return "content is not a known image format", nil
+	}
+	return "", nil
+}
+
+// looksLikeImage checks the magic bytes at the beginning of a file for a known image format.
+// SVGs being text, we check that the root element of the file, read on from rest, is an svg element instead.
+func looksLikeImage(header []byte, rest io.Reader) bool {
+	if strings.HasPrefix(http.DetectContentType(header), "image/") {
+		return true
+	}
+	return hasSVGRoot(io.LimitReader(io.MultiReader(bytes.NewReader(header), rest), maxSVGPrologLength))
+}
+
+// hasSVGRoot tells if the root element of the passed XML document is an svg element.
+// What may come before it in XML, such as a declaration, a doctype or comments, is skipped.
+func hasSVGRoot(r io.Reader) bool {
+	decoder := xml.NewDecoder(r)
+	// Only the name of the root element matters, which does not depend on the declared encoding
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			return token.Name.Local == "svg"
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				// Text before the root element: this is no XML document
+				return false
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package checkdoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+// A minimal PNG header, enough to be recognized as such.
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestBuildReportImages(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Images\n\n![ok](img/arch.png)\n![missing](img/gone.png)\n" +
+			"![remote](https://open.ch/logo.png)\n![nested](/docs/img/flow.svg#frag)\n",
+		"img/arch.png":      pngHeader,
+		"docs/img/flow.svg": `<svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+	})
+
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nodes))
+	assert.Equal(t, []string{"img/arch.png", "img/gone.png", "docs/img/flow.svg"}, nodes[0].NormalizedLocalImageLinks)
+	assert.Empty(t, nodes[0].NormalizedLocalRelativeLinks, "Images should not be considered as links")
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	assert.Equal(t, []string{"img/gone.png"}, reports["README.md"].DeadImageLinks)
+	assert.Equal(t, 0, len(reports["README.md"].DeadLinks))
+	assert.False(t, ValidateReports(reports))
+}
+
+func TestCheckImages(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"img/arch.png":   pngHeader,
+		"img/flow.svg":   `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+		"img/fake.png":   "I am only text",
+		"img/big.png":    pngHeader + string(make([]byte, 100)),
+		"img/a-dir/keep": "",
+	})
+	reports := map[string]NodeReport{
+		"README.md": {
+			Node: LinkGraphNode{
+				RelativePath: "README.md",
+				NormalizedLocalImageLinks: []string{
+					"img/arch.png", "img/flow.svg", "img/fake.png", "img/big.png", "img/a-dir", "img/gone.png",
+				},
+			},
+			DeadImageLinks: []string{"img/gone.png"},
+		},
+	}
+
+	err := CheckImages(treeRoot, reports, ImageChecks{})
+	assert.NoError(t, err)
+	assert.Empty(t, reports["README.md"].InvalidImages, "Nothing should be checked if no check is enabled")
+
+	err = CheckImages(treeRoot, reports, ImageChecks{VerifyContent: true, MaxSize: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, []InvalidImage{
+		{Path: "img/fake.png", Reason: "content is not a known image format"},
+		{Path: "img/big.png", Reason: "116 bytes exceeds the maximum of 100 bytes"},
+		{Path: "img/a-dir", Reason: "not a regular file"},
+	}, reports["README.md"].InvalidImages)
+}
+
+func TestLooksLikeImageSVG(t *testing.T) {
+	licence := "<!--\n" + strings.Repeat("Licensed under the Apache License, Version 2.0.\n", 20) + "-->\n"
+	svg := `<?xml version="1.0" encoding="ISO-8859-1" standalone="no"?>` + "\n" + licence +
+		`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">` + "\n" +
+		`<svg:svg xmlns:svg="http://www.w3.org/2000/svg"></svg:svg>`
+	assert.True(t, looksLikeImage([]byte(svg[:sniffLength]), strings.NewReader(svg[sniffLength:])),
+		"The root element should be found after the first bytes")
+
+	html := "<html><body><svg></svg></body></html>"
+	assert.False(t, looksLikeImage([]byte(html), strings.NewReader("")), "Only the root element should count")
+	text := "Draw it with <svg> tags"
+	assert.False(t, looksLikeImage([]byte(text), strings.NewReader("")))
+}
@@ -0,0 +1,57 @@
+package checkdoc
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// ImpactedDocument is a document linking to some of the files passed to FindImpactedDocuments.
+type ImpactedDocument struct {
+	DocumentPath string   `json:"document"` // Path of the document, relative to the root
+	Files        []string `json:"files"`    // The passed files the document refers to, sorted
+}
+
+// FindImpactedDocuments returns the documents linking to any of the passed files, relative to the root,
+// or to any of their parent directories, sorted by document. It is the reverse of BuildLocalPathSet:
+// the files need not exist anymore, so that documents referring to deleted files are found too.
+// Passed files that are documents themselves are ignored.
+func FindImpactedDocuments(nodes []LinkGraphNode, files []string) []ImpactedDocument {
+	documents := make(map[string]bool)
+	for _, node := range nodes {
+		documents[node.RelativePath] = true
+	}
+	var sourceFiles []string
+	for _, file := range files {
+		file = path.Clean(strings.TrimPrefix(file, "./"))
+		if !documents[file] {
+			sourceFiles = append(sourceFiles, file)
+		}
+	}
+	slices.Sort(sourceFiles)
+	sourceFiles = slices.Compact(sourceFiles)
+
+	var impacted []ImpactedDocument
+	for _, node := range nodes {
+		links := slices.Concat(node.NormalizedLocalRelativeLinks, node.NormalizedLocalImageLinks)
+		var referenced []string
+		for _, file := range sourceFiles {
+			if slices.ContainsFunc(links, func(link string) bool { return refersTo(link, file) }) {
+				referenced = append(referenced, file)
+			}
+		}
+		if len(referenced) > 0 {
+			impacted = append(impacted, ImpactedDocument{DocumentPath: node.RelativePath, Files: referenced})
+		}
+	}
+	slices.SortFunc(impacted, func(a, b ImpactedDocument) int {
+		return strings.Compare(a.DocumentPath, b.DocumentPath)
+	})
+	return impacted
+}
+
+// refersTo tells if a normalized link points to file, or to one of its parent directories.
+func refersTo(link string, file string) bool {
+	link = strings.TrimSuffix(link, "/")
+	return link != "" && (link == file || strings.HasPrefix(file, link+"/"))
+}
@@ -0,0 +1,24 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImpactedDocuments(t *testing.T) {
+	nodes := []LinkGraphNode{
+		{RelativePath: "README.md", NormalizedLocalRelativeLinks: []string{"tools/run.sh", "docs/install.md"}},
+		{RelativePath: "docs/install.md", NormalizedLocalRelativeLinks: []string{"tools"}},
+		{RelativePath: "docs/arch.md", NormalizedLocalImageLinks: []string{"docs/img/arch.png"}},
+		{RelativePath: "docs/other.md", NormalizedLocalRelativeLinks: []string{"tool"}},
+	}
+
+	assert.Equal(t, []ImpactedDocument{
+		{DocumentPath: "README.md", Files: []string{"tools/run.sh"}},
+		{DocumentPath: "docs/arch.md", Files: []string{"docs/img/arch.png"}},
+		{DocumentPath: "docs/install.md", Files: []string{"tools/lib/new.sh", "tools/run.sh"}},
+	}, FindImpactedDocuments(nodes, []string{"./tools/run.sh", "docs/img/arch.png", "tools/lib/new.sh", "tools/run.sh"}))
+
+	assert.Empty(t, FindImpactedDocuments(nodes, []string{"docs/install.md", "other/run.sh"}))
+}
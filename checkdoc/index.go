@@ -0,0 +1,116 @@
+package checkdoc
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Markers delimiting the region of a document that holds the generated index
+const (
+	IndexStartMarker = "<!-- checkdoc:index:start -->"
+	IndexEndMarker   = "<!-- checkdoc:index:end -->"
+)
+
+// indexDirectory is a directory of the generated index, with the documents and directories it contains.
+type indexDirectory struct {
+	name        string
+	index       *LinkGraphNode // The implicit index of the directory, if it is a document
+	documents   []LinkGraphNode
+	directories map[string]*indexDirectory
+}
+
+// BuildIndex generates a markdown sitemap of the passed documents, as nested lists following the directories.
+// Each document is titled from its first heading, or else from its file name. A directory is listed
+// under the title of its implicit index, if it has one.
+// Links are relative to the document the index is written to, indexPath, which is left out of the index.
+// An empty indexPath means links relative to the root.
+func BuildIndex(nodes []LinkGraphNode, implicitIndexes []string, indexPath string) string {
+	root := &indexDirectory{directories: make(map[string]*indexDirectory)}
+	for _, node := range nodes {
+		if node.RelativePath == indexPath {
+			continue
+		}
+		directory := root
+		dirPath := filepath.Dir(node.RelativePath)
+		if dirPath != "." {
+			for _, name := range strings.Split(dirPath, "/") {
+				if directory.directories[name] == nil {
+					directory.directories[name] = &indexDirectory{name: name, directories: make(map[string]*indexDirectory)}
+				}
+				directory = directory.directories[name]
+			}
+		}
+		if directory != root && directory.index == nil && slices.Contains(implicitIndexes, filepath.Base(node.RelativePath)) {
+			directory.index = &node
+			continue
+		}
+		directory.documents = append(directory.documents, node)
+	}
+
+	linkBase := "."
+	if indexPath != "" {
+		linkBase = filepath.Dir(indexPath)
+	}
+	var sb strings.Builder
+	writeIndexDirectory(&sb, root, linkBase, "")
+	return sb.String()
+}
+
+func writeIndexDirectory(sb *strings.Builder, directory *indexDirectory, linkBase string, indent string) {
+	slices.SortFunc(directory.documents, func(a, b LinkGraphNode) int {
+		return strings.Compare(a.RelativePath, b.RelativePath)
+	})
+	for _, document := range directory.documents {
+		fmt.Fprintf(sb, "%s- %s\n", indent, indexLink(document, linkBase))
+	}
+
+	for _, name := range sortedKeys(directory.directories) {
+		subdirectory := directory.directories[name]
+		if subdirectory.index != nil {
+			fmt.Fprintf(sb, "%s- %s\n", indent, indexLink(*subdirectory.index, linkBase))
+		} else {
+			fmt.Fprintf(sb, "%s- %s/\n", indent, name)
+		}
+		writeIndexDirectory(sb, subdirectory, linkBase, indent+"  ")
+	}
+}
+
+func indexLink(node LinkGraphNode, linkBase string) string {
+	title := node.Document.Title
+	if title == "" {
+		title = filepath.Base(node.RelativePath)
+	}
+	destination, err := filepath.Rel(linkBase, node.RelativePath)
+	if err != nil {
+		// Both paths are relative to the same root, this can't happen.
+		destination = node.RelativePath
+	}
+	title = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(title)
+	return fmt.Sprintf("[%s](%s)", title, filepath.ToSlash(destination))
+}
+
+// UpdateIndexRegion replaces whatever is between the index markers of a document's source with the passed index.
+// The rest of the source is kept as is. It fails if the source does not contain the markers, in that order.
+func UpdateIndexRegion(source []byte, index string) ([]byte, error) {
+	return replaceRegion(source, "index", IndexStartMarker, IndexEndMarker, index)
+}
+
+// replaceRegion replaces whatever is between the passed markers of a source with content.
+// It fails if the source does not contain the markers, in that order: errors name the markers with the passed label.
+func replaceRegion(source []byte, label string, startMarker string, endMarker string, content string) ([]byte, error) {
+	start := bytes.Index(source, []byte(startMarker))
+	if start < 0 {
+		return nil, fmt.Errorf("missing %s start marker %s", label, startMarker)
+	}
+	regionStart := start + len(startMarker)
+	end := bytes.Index(source[regionStart:], []byte(endMarker))
+	if end < 0 {
+		return nil, fmt.Errorf("missing %s end marker %s after the start marker", label, endMarker)
+	}
+	regionEnd := regionStart + end
+
+	return slices.Concat(source[:regionStart], []byte("\n"+content), source[regionEnd:]), nil
+}
@@ -0,0 +1,53 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+func indexTestNodes() []LinkGraphNode {
+	node := func(path string, title string) LinkGraphNode {
+		return LinkGraphNode{RelativePath: path, Document: markdown.Document{Title: title}}
+	}
+	return []LinkGraphNode{
+		node("docs/install.md", "Install"),
+		node("README.md", "Root"),
+		node("docs/README.md", "Documentation"),
+		node("docs/api/v1.md", "API [v1]"),
+		node("CHANGELOG.md", ""),
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	assert.Equal(t, `- [CHANGELOG.md](CHANGELOG.md)
+- [Root](README.md)
+- [Documentation](docs/README.md)
+  - [Install](docs/install.md)
+  - api/
+    - [API \[v1\]](docs/api/v1.md)
+`, BuildIndex(indexTestNodes(), []string{"README.md"}, ""))
+
+	assert.Equal(t, `- [CHANGELOG.md](../CHANGELOG.md)
+- [Root](../README.md)
+- docs/
+  - [Install](install.md)
+  - api/
+    - [API \[v1\]](api/v1.md)
+`, BuildIndex(indexTestNodes(), []string{"README.md"}, "docs/README.md"))
+}
+
+func TestUpdateIndexRegion(t *testing.T) {
+	source := "# Root\n\n" + IndexStartMarker + "\n- [Stale](stale.md)\n" + IndexEndMarker + "\n\nMore text\n"
+
+	updated, err := UpdateIndexRegion([]byte(source), "- [Fresh](fresh.md)\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "# Root\n\n"+IndexStartMarker+"\n- [Fresh](fresh.md)\n"+IndexEndMarker+"\n\nMore text\n", string(updated))
+
+	_, err = UpdateIndexRegion([]byte("# Root\n"), "")
+	assert.ErrorContains(t, err, "missing index start marker")
+	_, err = UpdateIndexRegion([]byte(IndexEndMarker+"\n"+IndexStartMarker), "")
+	assert.ErrorContains(t, err, "missing index end marker")
+}
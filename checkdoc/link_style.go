@@ -0,0 +1,167 @@
+package checkdoc
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LinkStyleReason tells which rule of the link style a link breaks.
+type LinkStyleReason string
+
+const (
+	// RootAbsolute means the link starts with a '/', while root-absolute links are forbidden
+	RootAbsolute LinkStyleReason = "root-absolute"
+	// TooManyParentLevels means the link climbs up more '..' levels than allowed
+	TooManyParentLevels LinkStyleReason = "too-many-parent-levels"
+	// NotShortest means an equivalent, shorter link exists
+	NotShortest LinkStyleReason = "not-shortest"
+)
+
+// LinkStyle is a policy on how local links are written.
+type LinkStyle struct {
+	ForbidRootAbsolute bool // Links may not start with a '/'
+	ForbidParentLevels bool // Relative links may not climb up with '..' at all
+	MaxParentLevels    int  // How many '..' levels relative links may climb up. No limit if 0
+	RequireShortest    bool // Links must be the shortest of their relative and root-absolute forms
+}
+
+// Enabled tells if the policy has any rule at all. The zero value has none.
+func (s LinkStyle) Enabled() bool {
+	return s.ForbidRootAbsolute || s.ForbidParentLevels || s.MaxParentLevels > 0 || s.RequireShortest
+}
+
+// allowsParentLevels tells if a relative link may climb up the passed number of '..' levels.
+func (s LinkStyle) allowsParentLevels(levels int) bool {
+	switch {
+	case s.ForbidParentLevels:
+		return levels == 0
+	case s.MaxParentLevels > 0:
+		return levels <= s.MaxParentLevels
+	default:
+		return true
+	}
+}
+
+// LinkStyleIssue is a local link that does not follow the link style.
+type LinkStyleIssue struct {
+	Destination string          `json:"destination"`          // The link destination, as written in the document
+	Target      string          `json:"target"`               // The link, normalized relative to the root
+	Reason      LinkStyleReason `json:"reason"`               // The first rule the link breaks
+	Suggestion  string          `json:"suggestion,omitempty"` // An equivalent destination following the style, if any
+}
+
+// CheckLinkStyle checks the local links and images of the reported nodes against the passed link style,
+// and records the ones that don't follow it as LinkStyleIssues in the corresponding report.
+func CheckLinkStyle(treeRoot string, reports map[string]NodeReport, style LinkStyle) {
+	if !style.Enabled() {
+		return
+	}
+	for documentPath, report := range reports {
+		var issues []LinkStyleIssue
+		forEachLocalLink(treeRoot, documentPath, report.Node, func(destination string, normalized string) {
+			if issue, found := checkLinkStyle(documentPath, destination, normalized, style); found {
+				issues = append(issues, issue)
+			}
+		})
+		report.LinkStyleIssues = issues
+		reports[documentPath] = report
+	}
+}
+
+func checkLinkStyle(documentPath string, destination string, normalized string, style LinkStyle) (LinkStyleIssue, bool) {
+	linkPath, anchor, hasAnchor := strings.Cut(destination, "#")
+	if linkPath == "" || normalized == "" {
+		// Nothing to rewrite for links to the root itself
+		return LinkStyleIssue{}, false
+	}
+	suffix := ""
+	if strings.HasSuffix(linkPath, "/") {
+		suffix = "/"
+	}
+	if hasAnchor {
+		suffix += "#" + anchor
+	}
+
+	relative, err := filepath.Rel(filepath.Dir(documentPath), normalized)
+	if err != nil {
+		// Both paths are relative to the same root, this can't happen.
+		return LinkStyleIssue{}, false
+	}
+	relative = filepath.ToSlash(relative)
+	absolute := "/" + normalized
+
+	var candidates []string
+	if style.allowsParentLevels(parentLevels(relative)) {
+		candidates = append(candidates, relative)
+	}
+	if !style.ForbidRootAbsolute {
+		candidates = append(candidates, absolute)
+	}
+
+	var reason LinkStyleReason
+	switch {
+	case strings.HasPrefix(linkPath, "/") && style.ForbidRootAbsolute:
+		reason = RootAbsolute
+	case !strings.HasPrefix(linkPath, "/") && !style.allowsParentLevels(parentLevels(path.Clean(linkPath))):
+		reason = TooManyParentLevels
+	case style.RequireShortest && len(candidates) > 0 &&
+		len(strings.TrimSuffix(linkPath, "/")) > len(shortest(candidates)):
+		reason = NotShortest
+	default:
+		return LinkStyleIssue{}, false
+	}
+
+	issue := LinkStyleIssue{Destination: destination, Target: normalized, Reason: reason}
+	if len(candidates) > 0 {
+		suggestion := candidates[0]
+		if style.RequireShortest {
+			suggestion = shortest(candidates)
+		}
+		issue.Suggestion = suggestion + suffix
+	}
+	return issue, true
+}
+
+// parentLevels counts how many levels a clean relative path climbs up.
+func parentLevels(relativePath string) int {
+	levels := 0
+	for strings.HasPrefix(relativePath, "../") || relativePath == ".." {
+		levels++
+		relativePath = strings.TrimPrefix(strings.TrimPrefix(relativePath, ".."), "/")
+	}
+	return levels
+}
+
+// shortest returns the shortest of the passed paths, the first one on a tie.
+func shortest(paths []string) string {
+	best := paths[0]
+	for _, p := range paths[1:] {
+		if len(p) < len(best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// PlanLinkStyleFixes returns the fixes rewriting the links found by CheckLinkStyle to their suggestion,
+// sorted by document. Links without a suggestion are left alone.
+func PlanLinkStyleFixes(reports map[string]NodeReport) []LinkFix {
+	var fixes []LinkFix
+	for documentPath, report := range reports {
+		for _, issue := range report.LinkStyleIssues {
+			if issue.Suggestion == "" {
+				continue
+			}
+			fixes = append(fixes, LinkFix{
+				DocumentPath:   documentPath,
+				DeadLink:       issue.Target,
+				Target:         issue.Target,
+				OldDestination: issue.Destination,
+				NewDestination: issue.Suggestion,
+			})
+		}
+	}
+	sortFixes(fixes)
+	return fixes
+}
@@ -0,0 +1,85 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestCheckLinkStyle(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "# Root\n\n[install](/docs/install.md#setup) [deep](./a/b/c/README.md) [dir](./docs/)\n",
+		"docs/install.md": "# Install\n\n[root](../README.md) [abs](/README.md)\n",
+		"a/b/c/README.md": "# C\n\n[root](../../../README.md) [install](/docs/install.md) [self](./README.md)\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	CheckLinkStyle(treeRoot, reports, LinkStyle{ForbidRootAbsolute: true, MaxParentLevels: 2})
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "/docs/install.md#setup", Target: "docs/install.md", Reason: RootAbsolute,
+			Suggestion: "docs/install.md#setup"},
+	}, reports["README.md"].LinkStyleIssues)
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "/README.md", Target: "README.md", Reason: RootAbsolute, Suggestion: "../README.md"},
+	}, reports["docs/install.md"].LinkStyleIssues)
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "../../../README.md", Target: "README.md", Reason: TooManyParentLevels},
+		{Destination: "/docs/install.md", Target: "docs/install.md", Reason: RootAbsolute},
+	}, reports["a/b/c/README.md"].LinkStyleIssues)
+
+	reports = BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	CheckLinkStyle(treeRoot, reports, LinkStyle{RequireShortest: true})
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "/docs/install.md#setup", Target: "docs/install.md", Reason: NotShortest,
+			Suggestion: "docs/install.md#setup"},
+		{Destination: "./a/b/c/README.md", Target: "a/b/c/README.md", Reason: NotShortest,
+			Suggestion: "a/b/c/README.md"},
+		{Destination: "./docs/", Target: "docs", Reason: NotShortest, Suggestion: "docs/"},
+	}, reports["README.md"].LinkStyleIssues)
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "../README.md", Target: "README.md", Reason: NotShortest, Suggestion: "/README.md"},
+	}, reports["docs/install.md"].LinkStyleIssues)
+	assert.Equal(t, []LinkStyleIssue{
+		{Destination: "../../../README.md", Target: "README.md", Reason: NotShortest, Suggestion: "/README.md"},
+		{Destination: "./README.md", Target: "a/b/c/README.md", Reason: NotShortest, Suggestion: "README.md"},
+	}, reports["a/b/c/README.md"].LinkStyleIssues)
+
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "README.md", DeadLink: "docs/install.md", Target: "docs/install.md",
+			OldDestination: "/docs/install.md#setup", NewDestination: "docs/install.md#setup"},
+		{DocumentPath: "README.md", DeadLink: "a/b/c/README.md", Target: "a/b/c/README.md",
+			OldDestination: "./a/b/c/README.md", NewDestination: "a/b/c/README.md"},
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "docs",
+			OldDestination: "./docs/", NewDestination: "docs/"},
+		{DocumentPath: "a/b/c/README.md", DeadLink: "README.md", Target: "README.md",
+			OldDestination: "../../../README.md", NewDestination: "/README.md"},
+		{DocumentPath: "a/b/c/README.md", DeadLink: "a/b/c/README.md", Target: "a/b/c/README.md",
+			OldDestination: "./README.md", NewDestination: "README.md"},
+		{DocumentPath: "docs/install.md", DeadLink: "README.md", Target: "README.md",
+			OldDestination: "../README.md", NewDestination: "/README.md"},
+	}, PlanLinkStyleFixes(reports))
+}
+
+func TestParentLevels(t *testing.T) {
+	assert.Equal(t, 0, parentLevels("docs/a.md"))
+	assert.Equal(t, 1, parentLevels("../a.md"))
+	assert.Equal(t, 3, parentLevels("../../../a.md"))
+	assert.Equal(t, 2, parentLevels("../.."))
+}
+
+func TestLinkStyleParentLevels(t *testing.T) {
+	assert.False(t, LinkStyle{}.Enabled(), "The zero value should have no rule")
+
+	issue, found := checkLinkStyle("docs/install.md", "../README.md", "README.md", LinkStyle{ForbidParentLevels: true})
+	assert.True(t, found)
+	assert.Equal(t, LinkStyleIssue{Destination: "../README.md", Target: "README.md", Reason: TooManyParentLevels,
+		Suggestion: "/README.md"}, issue)
+	_, found = checkLinkStyle("docs/install.md", "../README.md", "README.md", LinkStyle{MaxParentLevels: 1})
+	assert.False(t, found)
+	_, found = checkLinkStyle("a/b/c/README.md", "../../../README.md", "README.md", LinkStyle{MaxParentLevels: 2})
+	assert.True(t, found)
+}
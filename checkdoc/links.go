@@ -0,0 +1,68 @@
+package checkdoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// LinkRecord is a local link or image found in a document.
+type LinkRecord struct {
+	Source string `json:"source"` // Path of the document containing the link, relative to the root
+	Line   int    `json:"line"`   // Line of the link in the document, 0 if it could not be located in the source
+	Link   string `json:"link"`   // The link destination, as written in the document
+	Target string `json:"target"` // The link, normalized relative to the root. Directories end with a '/'.
+}
+
+// ListLinks returns all local links and images of the passed nodes, sorted by source, line and link.
+// A link appearing several times in a document is listed once per line.
+// It fails if a link points to something that does not exist.
+func ListLinks(treeRoot string, nodes []LinkGraphNode, parser markdown.Parser) ([]LinkRecord, error) {
+	var records []LinkRecord
+	for _, node := range nodes {
+		source, err := os.ReadFile(filepath.Join(treeRoot, node.RelativePath))
+		if err != nil {
+			return nil, err
+		}
+		var linkErr error
+		forEachLocalLink(treeRoot, node.RelativePath, node, func(destination string, normalized string) {
+			if linkErr != nil {
+				return
+			}
+			stat, err := os.Stat(filepath.Join(treeRoot, normalized))
+			if err != nil {
+				linkErr = fmt.Errorf("link %s in %s: %w", destination, node.RelativePath, err)
+				return
+			}
+			target := normalized
+			if stat.IsDir() && !strings.HasSuffix(target, "/") {
+				target += "/"
+			}
+			lines := destinationLines(parser, source, destination)
+			if len(lines) == 0 {
+				lines = []int{0}
+			}
+			for _, line := range lines {
+				records = append(records, LinkRecord{Source: node.RelativePath, Line: line, Link: destination, Target: target})
+			}
+		})
+		if linkErr != nil {
+			return nil, linkErr
+		}
+	}
+
+	slices.SortFunc(records, func(a, b LinkRecord) int {
+		if a.Source != b.Source {
+			return strings.Compare(a.Source, b.Source)
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.Link, b.Link)
+	})
+	return records, nil
+}
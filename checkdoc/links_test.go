@@ -0,0 +1,37 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestListLinks(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[script](bin/run.sh) [docs](docs)\n\n![img](docs/img.png) [again](bin/run.sh)\n" +
+			"[ext](https://open.ch)\n",
+		"docs/README.md": "# Docs\n\n[script](../bin/run.sh#L3)\n",
+		"docs/img.png":   "png",
+		"bin/run.sh":     "#!/bin/sh\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	records, err := ListLinks(treeRoot, nodes, blackfridayParser(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []LinkRecord{
+		{Source: "README.md", Line: 3, Link: "bin/run.sh", Target: "bin/run.sh"},
+		{Source: "README.md", Line: 3, Link: "docs", Target: "docs/"},
+		{Source: "README.md", Line: 5, Link: "bin/run.sh", Target: "bin/run.sh"},
+		{Source: "README.md", Line: 5, Link: "docs/img.png", Target: "docs/img.png"},
+		{Source: "docs/README.md", Line: 3, Link: "../bin/run.sh#L3", Target: "bin/run.sh"},
+	}, records)
+
+	treeRoot = testtree.Write(t, map[string]string{"README.md": "# Root\n\n[gone](gone.sh)\n"})
+	nodes, err = BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	_, err = ListLinks(treeRoot, nodes, blackfridayParser(t))
+	assert.ErrorContains(t, err, "link gone.sh in README.md")
+}
@@ -0,0 +1,117 @@
+package checkdoc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// MovedPath returns where path ends up when oldPath, a file or a directory, is moved to newPath.
+// Paths that are not affected by the move are returned as is.
+func MovedPath(path string, oldPath string, newPath string) string {
+	if path == oldPath {
+		return newPath
+	}
+	if suffix, found := strings.CutPrefix(path, oldPath+"/"); found {
+		return newPath + "/" + suffix
+	}
+	return path
+}
+
+// PlanMove returns the link fixes needed to keep the documentation consistent when moving oldPath to newPath,
+// both relative to the tree root:
+//   - inbound links to the moved file or directory, or to anything it contains, are updated.
+//     This includes links to a directory that resolved implicitly to the moved file.
+//   - relative links within the moved documents are updated so that they still resolve from their new location.
+//
+// The DocumentPath of the returned fixes is the path of the document before the move,
+// and their DeadLink is the former target of the link.
+func PlanMove(
+	treeRoot string,
+	nodes []LinkGraphNode,
+	implicitIndexes []string,
+	oldPath string,
+	newPath string,
+) []LinkFix {
+	var fixes []LinkFix
+	sanitizedRoot := strings.TrimSuffix(treeRoot, "/") + "/"
+
+	for _, node := range nodes {
+		newDocumentPath := MovedPath(node.RelativePath, oldPath, newPath)
+		planned := make(map[string]bool)
+		links := slices.Concat(node.Document.Links, node.Document.Images,
+			definitionLinks(node.Document))
+		for _, link := range markdown.FilterLocalLinks(links) {
+			if planned[link.Destination] {
+				continue
+			}
+			planned[link.Destination] = true
+			normalized, err := normalizeLinksToRoot(sanitizedRoot, node.RelativePath,
+				keepLinksAsStrings([]markdown.Link{link}, true))
+			if err != nil {
+				continue
+			}
+			target := normalized[0]
+			destination := link.Destination
+			newTarget := MovedPath(target, oldPath, newPath)
+			if newTarget == target && implicitlyResolvesTo(treeRoot, implicitIndexes, target, oldPath) {
+				// The link now needs to point to the file itself, not to a directory.
+				newTarget = newPath
+				destination = withoutTrailingSlash(destination)
+			}
+			if newTarget == target && newDocumentPath == node.RelativePath {
+				// Neither the link's target nor the document moved
+				continue
+			}
+
+			newDestination := rewriteDestination(newDocumentPath, destination, newTarget)
+			if newDestination == link.Destination {
+				// This happens with root-absolute links to things that did not move
+				continue
+			}
+			fixes = append(fixes, LinkFix{
+				DocumentPath:   node.RelativePath,
+				DeadLink:       target,
+				Target:         newTarget,
+				OldDestination: link.Destination,
+				NewDestination: newDestination,
+			})
+		}
+	}
+
+	slices.SortStableFunc(fixes, func(a, b LinkFix) int {
+		return strings.Compare(a.DocumentPath, b.DocumentPath)
+	})
+	return fixes
+}
+
+// implicitlyResolvesTo tells if target is a directory that resolves to the moved file through an implicit index.
+func implicitlyResolvesTo(treeRoot string, implicitIndexes []string, target string, movedPath string) bool {
+	if filepath.Dir(movedPath) != target {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(treeRoot, target))
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	for _, indexFile := range implicitIndexes {
+		candidate := filepath.Join(target, indexFile)
+		if _, err := os.Stat(filepath.Join(treeRoot, candidate)); err == nil {
+			// The first existing index is the one the directory resolves to
+			return candidate == movedPath
+		}
+	}
+	return false
+}
+
+func withoutTrailingSlash(destination string) string {
+	path, anchor, hasAnchor := strings.Cut(destination, "#")
+	path = strings.TrimSuffix(path, "/")
+	if hasAnchor {
+		return path + "#" + anchor
+	}
+	return path
+}
@@ -0,0 +1,85 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestMovedPath(t *testing.T) {
+	assert.Equal(t, "guides/setup.md", MovedPath("docs/install.md", "docs/install.md", "guides/setup.md"))
+	assert.Equal(t, "guides/img/a.png", MovedPath("docs/img/a.png", "docs", "guides"))
+	assert.Equal(t, "docs-old/a.md", MovedPath("docs-old/a.md", "docs", "guides"))
+	assert.Equal(t, "README.md", MovedPath("README.md", "docs", "guides"))
+}
+
+func TestPlanMoveFile(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[install](docs/install.md#setup) [abs](/docs/install.md) [docs](docs/)\n",
+		"docs/install.md": "# Install\n\n[root](../README.md) [other](other.md) [abs](/README.md) " +
+			"![img](img/a.png)\n",
+		"docs/other.md":  "# Other\n\n[install](./install.md)\n",
+		"docs/img/a.png": "png",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	fixes := PlanMove(treeRoot, nodes, []string{"README.md"}, "docs/install.md", "guides/setup.md")
+
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "README.md", DeadLink: "docs/install.md", Target: "guides/setup.md",
+			OldDestination: "docs/install.md#setup", NewDestination: "guides/setup.md#setup"},
+		{DocumentPath: "README.md", DeadLink: "docs/install.md", Target: "guides/setup.md",
+			OldDestination: "/docs/install.md", NewDestination: "/guides/setup.md"},
+		{DocumentPath: "docs/install.md", DeadLink: "docs/other.md", Target: "docs/other.md",
+			OldDestination: "other.md", NewDestination: "../docs/other.md"},
+		{DocumentPath: "docs/install.md", DeadLink: "docs/img/a.png", Target: "docs/img/a.png",
+			OldDestination: "img/a.png", NewDestination: "../docs/img/a.png"},
+		{DocumentPath: "docs/other.md", DeadLink: "docs/install.md", Target: "guides/setup.md",
+			OldDestination: "./install.md", NewDestination: "../guides/setup.md"},
+	}, fixes)
+}
+
+func TestPlanMoveDirectory(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "# Root\n\n[docs](docs/) [install](docs/install.md) [img](docs/img/a.png)\n",
+		"docs/README.md":  "# Docs\n\n[install](install.md) [root](../README.md)\n",
+		"docs/install.md": "# Install\n",
+		"docs/img/a.png":  "png",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	fixes := PlanMove(treeRoot, nodes, []string{"README.md"}, "docs", "guides/docs")
+
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "guides/docs",
+			OldDestination: "docs/", NewDestination: "guides/docs/"},
+		{DocumentPath: "README.md", DeadLink: "docs/install.md", Target: "guides/docs/install.md",
+			OldDestination: "docs/install.md", NewDestination: "guides/docs/install.md"},
+		{DocumentPath: "README.md", DeadLink: "docs/img/a.png", Target: "guides/docs/img/a.png",
+			OldDestination: "docs/img/a.png", NewDestination: "guides/docs/img/a.png"},
+		{DocumentPath: "docs/README.md", DeadLink: "README.md", Target: "README.md",
+			OldDestination: "../README.md", NewDestination: "../../README.md"},
+	}, fixes)
+}
+
+func TestPlanMoveImplicitIndex(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":      "# Root\n\n[docs](docs/#intro) [docs again](./docs)\n",
+		"docs/README.md": "# Docs\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	fixes := PlanMove(treeRoot, nodes, []string{"README.md"}, "docs/README.md", "guides.md")
+
+	assert.Equal(t, []LinkFix{
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "guides.md",
+			OldDestination: "docs/#intro", NewDestination: "guides.md#intro"},
+		{DocumentPath: "README.md", DeadLink: "docs", Target: "guides.md",
+			OldDestination: "./docs", NewDestination: "./guides.md"},
+	}, fixes)
+}
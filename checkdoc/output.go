@@ -0,0 +1,74 @@
+package checkdoc
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+)
+
+// jsonReport is how reports are written as JSON: only documents with findings are listed.
+type jsonReport struct {
+	Valid                   bool             `json:"valid"`
+	Documents               []jsonNodeReport `json:"documents"`
+	DirectoriesWithoutIndex []string         `json:"directoriesWithoutIndex,omitempty"`
+}
+
+type jsonNodeReport struct {
+	Path            string           `json:"path"`
+	Orphan          bool             `json:"orphan"`
+	DeadLinks       []jsonDeadLink   `json:"deadLinks,omitempty"`
+	DirectoryLinks  []string         `json:"directoryWithoutIndex,omitempty"`
+	ImplicitLinks   []ImplicitLink   `json:"implicitLinks,omitempty"`
+	LinkStyleIssues []LinkStyleIssue `json:"linkStyleIssues,omitempty"`
+	DeadImageLinks  []jsonDeadLink   `json:"deadImageLinks,omitempty"`
+	InvalidImages   []InvalidImage   `json:"invalidImages,omitempty"`
+	ReferenceIssues []ReferenceIssue `json:"referenceIssues,omitempty"`
+	TooDeep         *DepthViolation  `json:"tooDeep,omitempty"`
+}
+
+type jsonDeadLink struct {
+	Link        string       `json:"link"`
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+}
+
+// WriteJSONReport writes the findings of the passed reports and the directories lacking a required index
+// as JSON to the passed writer, along with the outcome of their validation. Documents are sorted by path.
+func WriteJSONReport(w io.Writer, reports map[string]NodeReport, withoutIndex []string, valid bool) error {
+	output := jsonReport{Valid: valid, Documents: []jsonNodeReport{}, DirectoriesWithoutIndex: withoutIndex}
+	for path, report := range reports {
+		nodeReport := jsonNodeReport{
+			Path:            path,
+			Orphan:          report.IsOrphan,
+			DeadLinks:       toJSONDeadLinks(report.DeadLinks, report.Suggestions),
+			DeadImageLinks:  toJSONDeadLinks(report.DeadImageLinks, report.Suggestions),
+			DirectoryLinks:  report.DirectoryLinks,
+			ImplicitLinks:   report.ImplicitLinks,
+			LinkStyleIssues: report.LinkStyleIssues,
+			InvalidImages:   report.InvalidImages,
+			ReferenceIssues: report.ReferenceIssues,
+			TooDeep:         report.TooDeep,
+		}
+		if nodeReport.Orphan || len(nodeReport.DeadLinks) != 0 || len(nodeReport.DeadImageLinks) != 0 ||
+			len(nodeReport.DirectoryLinks) != 0 || len(nodeReport.ImplicitLinks) != 0 ||
+			len(nodeReport.LinkStyleIssues) != 0 ||
+			len(nodeReport.InvalidImages) != 0 || len(nodeReport.ReferenceIssues) != 0 || nodeReport.TooDeep != nil {
+			output.Documents = append(output.Documents, nodeReport)
+		}
+	}
+	slices.SortFunc(output.Documents, func(a, b jsonNodeReport) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+func toJSONDeadLinks(deadLinks []string, suggestions map[string][]Suggestion) []jsonDeadLink {
+	var toRet []jsonDeadLink
+	for _, deadLink := range deadLinks {
+		toRet = append(toRet, jsonDeadLink{Link: deadLink, Suggestions: suggestions[deadLink]})
+	}
+	return toRet
+}
@@ -0,0 +1,47 @@
+package checkdoc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSONReport(t *testing.T) {
+	reports := map[string]NodeReport{
+		"README.md": {
+			DeadLinks: []string{"docs/instal.md"},
+			Suggestions: map[string][]Suggestion{
+				"docs/instal.md": {{Path: "docs/install.md", Reason: SimilarPath, Distance: 1}},
+			},
+		},
+		"docs/install.md": {},
+		"docs/orphan.md":  {IsOrphan: true, ReferenceIssues: []ReferenceIssue{{Kind: UnusedDefinition, Label: "x", Line: 3}}},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteJSONReport(&out, reports, []string{"services/api"}, false))
+	assert.JSONEq(t, `{
+		"valid": false,
+		"documents": [
+			{
+				"path": "README.md",
+				"orphan": false,
+				"deadLinks": [{
+					"link": "docs/instal.md",
+					"suggestions": [{"path": "docs/install.md", "reason": "similar-path", "distance": 1}]
+				}]
+			},
+			{
+				"path": "docs/orphan.md",
+				"orphan": true,
+				"referenceIssues": [{"kind": "unused-definition", "label": "x", "line": 3}]
+			}
+		],
+		"directoriesWithoutIndex": ["services/api"]
+	}`, out.String())
+
+	out.Reset()
+	assert.NoError(t, WriteJSONReport(&out, map[string]NodeReport{}, nil, true))
+	assert.JSONEq(t, `{"valid": true, "documents": []}`, out.String())
+}
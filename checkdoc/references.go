@@ -0,0 +1,101 @@
+package checkdoc
+
+import (
+	"slices"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// ReferenceIssueKind tells what is wrong with a reference-style link or a reference definition.
+type ReferenceIssueKind string
+
+const (
+	// UndefinedReference is a reference-style link for which no definition exists
+	UndefinedReference ReferenceIssueKind = "undefined-reference"
+	// UnusedDefinition is a reference definition that no link refers to
+	UnusedDefinition ReferenceIssueKind = "unused-definition"
+	// DuplicateDefinition is a reference definition for a label that was already defined before
+	DuplicateDefinition ReferenceIssueKind = "duplicate-definition"
+)
+
+// ReferenceIssue is a problem found with reference-style links in a document.
+type ReferenceIssue struct {
+	Kind  ReferenceIssueKind `json:"kind"`
+	Label string             `json:"label"` // The normalized label of the reference
+	Line  int                `json:"line"`  // The line of the usage or definition at fault
+}
+
+// buildReferenceReport checks the references of each passed graph node for undefined, unused and duplicate labels.
+func buildReferenceReport(nodes []LinkGraphNode) map[string][]ReferenceIssue {
+	toRet := make(map[string][]ReferenceIssue)
+	for _, node := range nodes {
+		toRet[node.RelativePath] = checkReferences(node.Document.References)
+	}
+	return toRet
+}
+
+func checkReferences(refs markdown.References) []ReferenceIssue {
+	var issues []ReferenceIssue
+
+	defined := make(map[string]bool)
+	for _, definition := range refs.Definitions {
+		if defined[definition.Label] {
+			issues = append(issues, ReferenceIssue{Kind: DuplicateDefinition, Label: definition.Label, Line: definition.Line})
+		}
+		defined[definition.Label] = true
+	}
+
+	for _, usage := range refs.Usages {
+		if !defined[usage.Label] {
+			issues = append(issues, ReferenceIssue{Kind: UndefinedReference, Label: usage.Label, Line: usage.Line})
+		}
+	}
+
+	for _, definition := range unusedDefinitions(refs) {
+		issues = append(issues, ReferenceIssue{Kind: UnusedDefinition, Label: definition.Label, Line: definition.Line})
+	}
+
+	return issues
+}
+
+// unusedDefinitions returns the definitions no link refers to. Only the first definition of a label
+// is considered, as duplicates are reported on their own.
+func unusedDefinitions(refs markdown.References) []markdown.ReferenceDefinition {
+	used := make(map[string]bool)
+	for _, usage := range refs.Usages {
+		used[usage.Label] = true
+	}
+
+	var unused []markdown.ReferenceDefinition
+	seen := make(map[string]bool)
+	for _, definition := range refs.Definitions {
+		if !used[definition.Label] && !seen[definition.Label] {
+			unused = append(unused, definition)
+		}
+		seen[definition.Label] = true
+	}
+	return unused
+}
+
+// definitionLinks turns the definitions of the document that the parser may not have turned into links
+// into links, so that their destination can be checked as any other link: the unused definitions, and all
+// the definitions of a label defined several times, as parsers disagree on which one is used.
+// Destinations the document already links to are left out.
+func definitionLinks(document markdown.Document) []markdown.Link {
+	definitionCounts := make(map[string]int)
+	for _, definition := range document.References.Definitions {
+		definitionCounts[definition.Label]++
+	}
+	unused := unusedDefinitions(document.References)
+	var links []markdown.Link
+	for _, definition := range document.References.Definitions {
+		if definitionCounts[definition.Label] == 1 && !slices.Contains(unused, definition) {
+			continue
+		}
+		link := markdown.Link{Destination: definition.Destination}
+		if !slices.Contains(document.Links, link) && !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
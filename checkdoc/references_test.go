@@ -0,0 +1,53 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+func TestCheckReferences(t *testing.T) {
+	refs := markdown.References{
+		Definitions: []markdown.ReferenceDefinition{
+			{Label: "used", Destination: "used.md", Line: 10},
+			{Label: "unused", Destination: "unused.md", Line: 11},
+			{Label: "used", Destination: "used-again.md", Line: 12},
+		},
+		Usages: []markdown.ReferenceUsage{
+			{Label: "used", Line: 1},
+			{Label: "undefined", Line: 2},
+		},
+	}
+
+	assert.Equal(t, []ReferenceIssue{
+		{Kind: DuplicateDefinition, Label: "used", Line: 12},
+		{Kind: UndefinedReference, Label: "undefined", Line: 2},
+		{Kind: UnusedDefinition, Label: "unused", Line: 11},
+	}, checkReferences(refs))
+	assert.Empty(t, checkReferences(markdown.References{}))
+}
+
+func TestBuildReportReferences(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Refs\n\nSee [the docs][docs] and [nothing][missing].\n\n" +
+			"[docs]: docs/README.md\n[stale]: docs/gone.md\n[Docs]: docs/moved.md\n",
+		"docs/README.md": "# Docs\n",
+	})
+
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	assert.False(t, reports["docs/README.md"].IsOrphan)
+	assert.ElementsMatch(t, []string{"docs/gone.md", "docs/moved.md"}, reports["README.md"].DeadLinks,
+		"Unused and duplicate definitions should still be checked")
+	assert.Equal(t, []ReferenceIssue{
+		{Kind: DuplicateDefinition, Label: "docs", Line: 7},
+		{Kind: UndefinedReference, Label: "missing", Line: 3},
+		{Kind: UnusedDefinition, Label: "stale", Line: 6},
+	}, reports["README.md"].ReferenceIssues)
+	assert.False(t, ValidateReports(reports))
+}
@@ -0,0 +1,151 @@
+package checkdoc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Name of the directory where git keeps its data, which we never want to look into.
+const gitDirectory = ".git"
+
+// Relocator looks for the new location of the target of a dead link.
+// Both the dead link and the returned path are relative to the tree root.
+// The boolean is false if no new location could be found.
+type Relocator func(deadLink string) (string, bool)
+
+// Relocate tries each passed relocator in turn, and returns the first new location found
+// that actually exists below the tree root.
+func Relocate(treeRoot string, deadLink string, relocators []Relocator) (string, bool) {
+	for _, relocator := range relocators {
+		newPath, found := relocator(deadLink)
+		if !found || newPath == deadLink {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(treeRoot, newPath)); err == nil {
+			return newPath, true
+		}
+	}
+	return "", false
+}
+
+// NewGitRenameRelocator returns a relocator relying on the git history of the tree root: if a file was renamed,
+// possibly several times, links to its previous names are relocated to its latest name.
+// Links to a directory are relocated if all renamed files it contained ended up in the same new directory.
+func NewGitRenameRelocator(treeRoot string) (Relocator, error) {
+	renames, err := gitRenames(treeRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(deadLink string) (string, bool) {
+		if newPath, found := followRenames(renames, deadLink); found {
+			return newPath, true
+		}
+		return relocateDirectory(renames, deadLink)
+	}, nil
+}
+
+// gitRenames returns the renames recorded in the git history below treeRoot, as a map of old to new paths
+// relative to treeRoot.
+func gitRenames(treeRoot string) (map[string]string, error) {
+	gitCmd := exec.Command("git", "log", "--format=", "--name-status", "--diff-filter=R", "-M", "--relative", "--reverse")
+	gitCmd.Dir = treeRoot
+	output, err := gitCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the rename history of %s: %w", treeRoot, err)
+	}
+
+	renames := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		// Lines look like: R096<TAB>old/path<TAB>new/path
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 || !strings.HasPrefix(fields[0], "R") {
+			continue
+		}
+		renames[fields[1]] = fields[2]
+	}
+	return renames, scanner.Err()
+}
+
+// followRenames follows the renames of path up to its latest name.
+func followRenames(renames map[string]string, path string) (string, bool) {
+	current, found := renames[path]
+	if !found {
+		return "", false
+	}
+	// Guard against a file that was renamed back and forth
+	seen := map[string]bool{path: true}
+	for !seen[current] {
+		seen[current] = true
+		next, renamedAgain := renames[current]
+		if !renamedAgain {
+			break
+		}
+		current = next
+	}
+	return current, true
+}
+
+func relocateDirectory(renames map[string]string, dir string) (string, bool) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	newDir := ""
+	for oldPath := range renames {
+		if !strings.HasPrefix(oldPath, prefix) {
+			continue
+		}
+		newPath, _ := followRenames(renames, oldPath)
+		suffix := strings.TrimPrefix(oldPath, prefix)
+		if !strings.HasSuffix(newPath, "/"+suffix) {
+			// The file was renamed on its own: this tells us nothing about the directory.
+			continue
+		}
+		candidate := strings.TrimSuffix(newPath, "/"+suffix)
+		if newDir != "" && newDir != candidate {
+			// Files went to different places, we can't tell where the directory went.
+			return "", false
+		}
+		newDir = candidate
+	}
+	return newDir, newDir != ""
+}
+
+// NewBasenameRelocator returns a relocator looking for files below the tree root with the same name as the
+// dead link's target. A new location is only returned if a single such file exists.
+func NewBasenameRelocator(treeRoot string) (Relocator, error) {
+	byBasename := make(map[string][]string)
+	err := filepath.WalkDir(treeRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == gitDirectory {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(treeRoot, path)
+		if err != nil {
+			return err
+		}
+		byBasename[d.Name()] = append(byBasename[d.Name()], filepath.ToSlash(relativePath))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index the files below %s: %w", treeRoot, err)
+	}
+
+	return func(deadLink string) (string, bool) {
+		candidates := byBasename[filepath.Base(deadLink)]
+		if len(candidates) != 1 {
+			return "", false
+		}
+		return candidates[0], true
+	}, nil
+}
@@ -0,0 +1,84 @@
+package checkdoc
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	gitCmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=checkdoc", "GIT_AUTHOR_EMAIL=checkdoc@example.com",
+		"GIT_COMMITTER_NAME=checkdoc", "GIT_COMMITTER_EMAIL=checkdoc@example.com")
+	output, err := gitCmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+func TestFollowRenames(t *testing.T) {
+	renames := map[string]string{
+		"a.md":       "b.md",
+		"b.md":       "c.md",
+		"loop-a.md":  "loop-b.md",
+		"loop-b.md":  "loop-a.md",
+		"dir/one.md": "new-dir/one.md",
+		"dir/two.md": "new-dir/two.md",
+		"dir/tri.md": "elsewhere.md",
+		"odd/one.md": "x/one.md",
+		"odd/two.md": "y/two.md",
+	}
+
+	newPath, found := followRenames(renames, "a.md")
+	assert.True(t, found)
+	assert.Equal(t, "c.md", newPath)
+
+	newPath, found = followRenames(renames, "loop-a.md")
+	assert.True(t, found)
+	assert.Equal(t, "loop-a.md", newPath)
+
+	_, found = followRenames(renames, "never-renamed.md")
+	assert.False(t, found)
+
+	newDir, found := relocateDirectory(renames, "dir")
+	assert.True(t, found)
+	assert.Equal(t, "new-dir", newDir)
+
+	_, found = relocateDirectory(renames, "odd")
+	assert.False(t, found, "Files of the directory went to different places")
+}
+
+func TestGitRenameRelocator(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"docs/install.md": "# Install\n\nSome content so that git detects the rename.\n",
+	})
+	runGit(t, treeRoot, "init", "-q")
+	runGit(t, treeRoot, "add", "-A")
+	runGit(t, treeRoot, "commit", "-qm", "init")
+	assert.NoError(t, os.MkdirAll(filepath.Join(treeRoot, "guides"), 0755))
+	runGit(t, treeRoot, "mv", "docs/install.md", "guides/setup.md")
+	runGit(t, treeRoot, "commit", "-qm", "move")
+
+	relocator, err := NewGitRenameRelocator(treeRoot)
+	assert.NoError(t, err)
+
+	newPath, found := relocator("docs/install.md")
+	assert.True(t, found)
+	assert.Equal(t, "guides/setup.md", newPath)
+
+	target, found := Relocate(treeRoot, "docs/install.md", []Relocator{relocator})
+	assert.True(t, found)
+	assert.Equal(t, "guides/setup.md", target)
+
+	_, found = Relocate(treeRoot, "docs/other.md", []Relocator{relocator})
+	assert.False(t, found)
+
+	_, err = NewGitRenameRelocator(t.TempDir())
+	assert.Error(t, err, "Should fail outside of a git repository")
+}
@@ -0,0 +1,117 @@
+package checkdoc
+
+//revive:disable:flag-parameter
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/denormal/go-gitignore"
+)
+
+// IndexRequirements tells which directories must contain an implicit index file.
+type IndexRequirements struct {
+	Patterns    []string // Glob patterns matched against directories relative to the root, see path.Match
+	MarkerFiles []string // Names of files whose presence in a directory requires an index, such as BUILD
+}
+
+// Enabled tells if any directory is required to have an index at all.
+func (r IndexRequirements) Enabled() bool {
+	return len(r.Patterns) != 0 || len(r.MarkerFiles) != 0
+}
+
+// FindDirectoriesWithoutIndex walks the tree below treeRoot and returns the sorted directories, relative to the root,
+// that are required to contain one of the implicit index files but don't.
+func FindDirectoriesWithoutIndex(
+	treeRoot string,
+	requirements IndexRequirements,
+	implicitIndexes []string,
+	respectGitIgnore bool,
+) ([]string, error) {
+	if !requirements.Enabled() {
+		return nil, nil
+	}
+	for _, pattern := range requirements.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid directory pattern %q: %w", pattern, err)
+		}
+	}
+
+	var gitIgnore gitignore.GitIgnore
+	if respectGitIgnore {
+		var err error
+		gitIgnore, err = gitignore.NewRepository(treeRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build up a gitignore from a git repository. "+
+				"Is treeRoot pointing to a git repository? It was: %s - %s", treeRoot, err)
+		}
+	}
+
+	var missing []string
+	err := filepath.WalkDir(treeRoot, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || absPath == treeRoot {
+			return nil
+		}
+		if d.Name() == gitDirectory || (gitIgnore != nil && gitIgnore.Absolute(absPath, true) != nil) {
+			return filepath.SkipDir
+		}
+		relativePath, err := filepath.Rel(treeRoot, absPath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if requiresIndex(absPath, relativePath, requirements) && !hasIndex(absPath, implicitIndexes) {
+			missing = append(missing, relativePath)
+		}
+		return nil
+	})
+	slices.Sort(missing)
+	return missing, err
+}
+
+func requiresIndex(absPath string, relativePath string, requirements IndexRequirements) bool {
+	for _, pattern := range requirements.Patterns {
+		// Patterns were validated beforehand
+		if matched, _ := path.Match(pattern, relativePath); matched {
+			return true
+		}
+	}
+	for _, markerFile := range requirements.MarkerFiles {
+		if info, err := os.Stat(filepath.Join(absPath, markerFile)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIndex(absPath string, implicitIndexes []string) bool {
+	for _, indexFile := range implicitIndexes {
+		if info, err := os.Stat(filepath.Join(absPath, indexFile)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDirectoryIndexes logs the passed directories that lack a required index.
+// It returns 'true' if there are none, and false otherwise.
+func ValidateDirectoryIndexes(withoutIndex []string) bool {
+	slog.Info("Checking for directories without a required index...")
+	if len(withoutIndex) == 0 {
+		slog.Info("No directories without a required index found.")
+		return true
+	}
+	slog.Error("Located some directories without a required index:")
+	for _, directory := range withoutIndex {
+		slog.Error(fmt.Sprintf("\t%s", directory))
+	}
+	return false
+}
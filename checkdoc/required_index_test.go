@@ -0,0 +1,43 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestFindDirectoriesWithoutIndex(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":                  "# Root\n",
+		"services/api/README.md":     "# API\n",
+		"services/api/main.go":       "package main\n",
+		"services/billing/main.go":   "package main\n",
+		"services/billing/sub/x.go":  "package sub\n",
+		"libs/auth/BUILD":            "",
+		"libs/auth/auth.go":          "package auth\n",
+		"libs/documented/BUILD":      "",
+		"libs/documented/README.md":  "# Documented\n",
+		"libs/BUILD/readme-less.txt": "BUILD is a directory here\n",
+	})
+	implicitIndexes := []string{"README.md"}
+
+	missing, err := FindDirectoriesWithoutIndex(treeRoot, IndexRequirements{}, implicitIndexes, false)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	missing, err = FindDirectoriesWithoutIndex(treeRoot, IndexRequirements{Patterns: []string{"services/*"}},
+		implicitIndexes, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"services/billing"}, missing)
+
+	missing, err = FindDirectoriesWithoutIndex(treeRoot, IndexRequirements{MarkerFiles: []string{"BUILD"}},
+		implicitIndexes, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"libs/auth"}, missing)
+
+	_, err = FindDirectoriesWithoutIndex(treeRoot, IndexRequirements{Patterns: []string{"services/["}},
+		implicitIndexes, false)
+	assert.ErrorContains(t, err, "invalid directory pattern")
+}
@@ -0,0 +1,225 @@
+package checkdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// LinkKind classifies links by what they point to.
+type LinkKind string
+
+const (
+	// DocumentLink points to another document, directly or through an implicit index
+	DocumentLink LinkKind = "document"
+	// DirectoryLink points to a directory without an implicit index
+	DirectoryLink LinkKind = "directory"
+	// FileLink points to a local file that is not a document, such as source code
+	FileLink LinkKind = "file"
+	// ImageLink embeds a local image
+	ImageLink LinkKind = "image"
+	// AnchorLink points to an anchor within the same document
+	AnchorLink LinkKind = "anchor"
+	// ExternalLink points outside the repository: urls, email addresses and inlined data
+	ExternalLink LinkKind = "external"
+	// DeadLink points to a local file or image that does not exist
+	DeadLink LinkKind = "dead"
+)
+
+// How many hub documents the statistics list
+const maxHubs = 5
+
+// Stats summarizes the shape of the documentation.
+type Stats struct {
+	Documents            int              `json:"documents"`
+	DocumentsByDirectory map[string]int   `json:"documentsByDirectory"`
+	Degrees              []DocumentDegree `json:"degrees"`  // By path
+	Hubs                 []DocumentDegree `json:"hubs"`     // The most linked documents, most linked first
+	DeadEnds             []string         `json:"deadEnds"` // Documents without links to other documents
+	AverageDepth         float64          `json:"averageDepth"`
+	MaxDepth             int              `json:"maxDepth"`
+	Unreachable          []string         `json:"unreachable"` // Documents that can't be reached from the roots
+	LinksByKind          map[LinkKind]int `json:"linksByKind"`
+}
+
+// DocumentDegree tells how many documents link to a document, and how many it links to.
+type DocumentDegree struct {
+	Path      string `json:"path"`
+	InDegree  int    `json:"inDegree"`
+	OutDegree int    `json:"outDegree"`
+}
+
+// BuildStats computes statistics about the documentation from the passed reports.
+// Click depths are the length of the shortest path from any of the root documents.
+func BuildStats(treeRoot string, reports map[string]NodeReport, implicitIndexes []string, roots []string) Stats {
+	graph := BuildGraph(treeRoot, reports, implicitIndexes)
+	stats := Stats{
+		Documents:            len(graph.Nodes),
+		DocumentsByDirectory: make(map[string]int),
+		Degrees:              []DocumentDegree{},
+		DeadEnds:             []string{},
+		Unreachable:          []string{},
+		LinksByKind:          make(map[LinkKind]int),
+	}
+
+	inDegrees := make(map[string]int)
+	outDegrees := make(map[string]int)
+	for _, edge := range graph.Edges {
+		outDegrees[edge.From]++
+		inDegrees[edge.To]++
+	}
+
+	paths := ShortestPaths(graph, roots)
+	totalDepth := 0
+	for _, node := range graph.Nodes {
+		stats.DocumentsByDirectory[node.Directory]++
+		stats.Degrees = append(stats.Degrees,
+			DocumentDegree{Path: node.Path, InDegree: inDegrees[node.Path], OutDegree: outDegrees[node.Path]})
+		if outDegrees[node.Path] == 0 {
+			stats.DeadEnds = append(stats.DeadEnds, node.Path)
+		}
+		path, reachable := paths[node.Path]
+		if !reachable {
+			stats.Unreachable = append(stats.Unreachable, node.Path)
+			continue
+		}
+		depth := len(path) - 1
+		totalDepth += depth
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+	}
+	if len(paths) > 0 {
+		stats.AverageDepth = float64(totalDepth) / float64(len(paths))
+	}
+
+	stats.Hubs = slices.DeleteFunc(slices.Clone(stats.Degrees), func(degree DocumentDegree) bool {
+		return degree.InDegree == 0
+	})
+	slices.SortStableFunc(stats.Hubs, func(a, b DocumentDegree) int {
+		return b.InDegree - a.InDegree
+	})
+	stats.Hubs = stats.Hubs[:min(maxHubs, len(stats.Hubs))]
+
+	for _, report := range reports {
+		countLinksByKind(treeRoot, implicitIndexes, reports, report, stats.LinksByKind)
+	}
+	return stats
+}
+
+// countLinksByKind adds the links of the reported document to the passed counts.
+func countLinksByKind(
+	treeRoot string,
+	implicitIndexes []string,
+	reports map[string]NodeReport,
+	report NodeReport,
+	counts map[LinkKind]int,
+) {
+	for _, link := range slices.Concat(report.Node.Document.Links, report.Node.Document.Images) {
+		if len(markdown.FilterLocalLinks([]markdown.Link{link})) != 0 {
+			continue
+		}
+		if strings.HasPrefix(link.Destination, "#") {
+			counts[AnchorLink]++
+		} else {
+			counts[ExternalLink]++
+		}
+	}
+
+	dead := make(map[string]bool)
+	for _, deadLink := range slices.Concat(report.DeadLinks, report.DeadImageLinks) {
+		dead[deadLink] = true
+	}
+	for _, image := range report.Node.NormalizedLocalImageLinks {
+		if dead[image] {
+			counts[DeadLink]++
+		} else {
+			counts[ImageLink]++
+		}
+	}
+	for _, link := range report.Node.NormalizedLocalRelativeLinks {
+		counts[localLinkKind(treeRoot, implicitIndexes, reports, link, dead[link])]++
+	}
+}
+
+func localLinkKind(
+	treeRoot string,
+	implicitIndexes []string,
+	reports map[string]NodeReport,
+	link string,
+	dead bool,
+) LinkKind {
+	if dead {
+		return DeadLink
+	}
+	if _, isDocument := reports[link]; isDocument {
+		return DocumentLink
+	}
+	for _, index := range implicitIndexFiles(treeRoot, implicitIndexes, link) {
+		if _, isDocument := reports[index]; isDocument {
+			return DocumentLink
+		}
+	}
+	if info, err := os.Stat(filepath.Join(treeRoot, link)); err == nil && info.IsDir() {
+		return DirectoryLink
+	}
+	return FileLink
+}
+
+// WriteStats writes the statistics as text.
+func WriteStats(w io.Writer, stats Stats) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Documents: %d\n", stats.Documents)
+	for _, directory := range sortedKeys(stats.DocumentsByDirectory) {
+		fmt.Fprintf(&sb, "\t%s: %d\n", directory, stats.DocumentsByDirectory[directory])
+	}
+
+	sb.WriteString("Links by kind:\n")
+	for _, kind := range []LinkKind{DocumentLink, DirectoryLink, FileLink, ImageLink, AnchorLink, ExternalLink, DeadLink} {
+		fmt.Fprintf(&sb, "\t%s: %d\n", kind, stats.LinksByKind[kind])
+	}
+
+	fmt.Fprintf(&sb, "Click depth: average %.2f, maximum %d\n", stats.AverageDepth, stats.MaxDepth)
+	writeStatsList(&sb, "Unreachable documents", stats.Unreachable)
+
+	sb.WriteString("Hubs (in/out):\n")
+	for _, hub := range stats.Hubs {
+		fmt.Fprintf(&sb, "\t%s: %d/%d\n", hub.Path, hub.InDegree, hub.OutDegree)
+	}
+	writeStatsList(&sb, "Dead ends", stats.DeadEnds)
+
+	sb.WriteString("Degrees (in/out):\n")
+	for _, degree := range stats.Degrees {
+		fmt.Fprintf(&sb, "\t%s: %d/%d\n", degree.Path, degree.InDegree, degree.OutDegree)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeStatsList(sb *strings.Builder, title string, paths []string) {
+	fmt.Fprintf(sb, "%s: %d\n", title, len(paths))
+	for _, path := range paths {
+		fmt.Fprintf(sb, "\t%s\n", path)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// WriteJSONStats writes the statistics as JSON.
+func WriteJSONStats(w io.Writer, stats Stats) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
@@ -0,0 +1,92 @@
+package checkdoc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestBuildStats(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[docs](docs/) [install](docs/install.md) [code](main.go) [web](https://open.ch)\n" +
+			"[top](#root) ![img](img.png) [dir](empty)\n",
+		"main.go":         "package main\n",
+		"img.png":         "png",
+		"empty/.keep":     "",
+		"docs/README.md":  "# Docs\n\n[install](install.md) [gone](gone.md) ![gone](gone.png)\n",
+		"docs/install.md": "# Install\n",
+		"docs/orphan.md":  "# Orphan\n\n[root](../README.md)\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+
+	stats := BuildStats(treeRoot, reports, []string{"README.md"}, []string{"README.md"})
+
+	assert.Equal(t, 4, stats.Documents)
+	assert.Equal(t, map[string]int{".": 1, "docs": 3}, stats.DocumentsByDirectory)
+	assert.Equal(t, []DocumentDegree{
+		{Path: "README.md", InDegree: 1, OutDegree: 2},
+		{Path: "docs/README.md", InDegree: 1, OutDegree: 1},
+		{Path: "docs/install.md", InDegree: 2, OutDegree: 0},
+		{Path: "docs/orphan.md", InDegree: 0, OutDegree: 1},
+	}, stats.Degrees)
+	assert.Equal(t, []DocumentDegree{
+		{Path: "docs/install.md", InDegree: 2, OutDegree: 0},
+		{Path: "README.md", InDegree: 1, OutDegree: 2},
+		{Path: "docs/README.md", InDegree: 1, OutDegree: 1},
+	}, stats.Hubs)
+	assert.Equal(t, []string{"docs/install.md"}, stats.DeadEnds)
+	assert.Equal(t, []string{"docs/orphan.md"}, stats.Unreachable)
+	assert.Equal(t, 1, stats.MaxDepth)
+	assert.InDelta(t, 2.0/3.0, stats.AverageDepth, 0.001)
+	assert.Equal(t, map[LinkKind]int{
+		DocumentLink:  4,
+		DirectoryLink: 1,
+		FileLink:      1,
+		ImageLink:     1,
+		AnchorLink:    1,
+		ExternalLink:  1,
+		DeadLink:      2,
+	}, stats.LinksByKind)
+}
+
+func TestWriteStats(t *testing.T) {
+	stats := Stats{
+		Documents:            2,
+		DocumentsByDirectory: map[string]int{".": 1, "docs": 1},
+		Degrees:              []DocumentDegree{{Path: "README.md", OutDegree: 1}, {Path: "docs/a.md", InDegree: 1}},
+		Hubs:                 []DocumentDegree{{Path: "docs/a.md", InDegree: 1}},
+		DeadEnds:             []string{"docs/a.md"},
+		AverageDepth:         0.5,
+		MaxDepth:             1,
+		LinksByKind:          map[LinkKind]int{DocumentLink: 1},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteStats(&out, stats))
+	assert.Equal(t, `Documents: 2
+	.: 1
+	docs: 1
+Links by kind:
+	document: 1
+	directory: 0
+	file: 0
+	image: 0
+	anchor: 0
+	external: 0
+	dead: 0
+Click depth: average 0.50, maximum 1
+Unreachable documents: 0
+Hubs (in/out):
+	docs/a.md: 1/0
+Dead ends: 1
+	docs/a.md
+Degrees (in/out):
+	README.md: 0/1
+	docs/a.md: 1/0
+`, out.String())
+}
@@ -0,0 +1,247 @@
+package checkdoc
+
+//revive:disable:flag-parameter
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/denormal/go-gitignore"
+)
+
+// SuggestionReason tells why a path is suggested as the intended target of a dead link.
+// Reasons are listed from the most to the least convincing.
+type SuggestionReason string
+
+const (
+	// CaseMismatch means the path only differs from the dead link by its case
+	CaseMismatch SuggestionReason = "case-mismatch"
+	// ExtensionMismatch means the dead link is missing the path's .md extension, or has an extra one
+	ExtensionMismatch SuggestionReason = "extension-mismatch"
+	// SameBasename means the path has the same name as the dead link, in another directory
+	SameBasename SuggestionReason = "same-basename"
+	// SimilarPath means the path is within a small edit distance of the dead link
+	SimilarPath SuggestionReason = "similar-path"
+)
+
+var reasonRanks = map[SuggestionReason]int{CaseMismatch: 0, ExtensionMismatch: 1, SameBasename: 2, SimilarPath: 3}
+
+// How many suggestions we keep for a single dead link
+const maxSuggestions = 3
+
+// Paths further away than this from a dead link are not considered similar
+const maxSuggestionDistance = 3
+
+// Suggestion is an existing path that a dead link may have been meant to point to.
+type Suggestion struct {
+	Path     string           `json:"path"`     // Path relative to the root
+	Reason   SuggestionReason `json:"reason"`   // Why it is suggested
+	Distance int              `json:"distance"` // Edit distance between the dead link and the path
+}
+
+// SuggestTargets adds to the passed reports the paths their dead links and images may have been meant
+// to point to, looked for among the files and directories below the tree root. The files ignored by git
+// are left out if respectGitIgnore is set.
+func SuggestTargets(treeRoot string, reports map[string]NodeReport, respectGitIgnore bool) {
+	deadLinks := make(map[string][]string)
+	for path, report := range reports {
+		deadLinks[path] = slices.Concat(report.DeadLinks, report.DeadImageLinks)
+	}
+	suggestions := buildSuggestionReport(treeRoot, deadLinks, respectGitIgnore)
+	for path, report := range reports {
+		report.Suggestions = suggestionsFor(suggestions, deadLinks[path])
+		reports[path] = report
+	}
+}
+
+// buildSuggestionReport looks for likely targets of each passed dead link among the files and directories
+// below the tree root. Only dead links for which something was found are present in the returned map.
+func buildSuggestionReport(treeRoot string, deadLinks map[string][]string, respectGitIgnore bool) map[string][]Suggestion {
+	suggestions := make(map[string][]Suggestion)
+
+	var allDeadLinks []string
+	for _, links := range deadLinks {
+		allDeadLinks = append(allDeadLinks, links...)
+	}
+	if len(allDeadLinks) == 0 {
+		// No need to walk the tree
+		return suggestions
+	}
+
+	candidates, err := listExistingPaths(treeRoot, respectGitIgnore)
+	if err != nil {
+		// Suggestions are a nice to have, and the tree was already walked successfully before.
+		return suggestions
+	}
+
+	for _, deadLink := range allDeadLinks {
+		if _, done := suggestions[deadLink]; done {
+			continue
+		}
+		if found := suggest(deadLink, candidates); len(found) > 0 {
+			suggestions[deadLink] = found
+		}
+	}
+	return suggestions
+}
+
+// suggestionsFor picks the suggestions relevant to the passed dead links, or nil if there are none.
+func suggestionsFor(suggestions map[string][]Suggestion, deadLinks []string) map[string][]Suggestion {
+	var toRet map[string][]Suggestion
+	for _, deadLink := range deadLinks {
+		if found, ok := suggestions[deadLink]; ok {
+			if toRet == nil {
+				toRet = make(map[string][]Suggestion)
+			}
+			toRet[deadLink] = found
+		}
+	}
+	return toRet
+}
+
+// listExistingPaths returns all files and directories below treeRoot, relative to it.
+// The ones ignored by git are left out if respectGitIgnore is set.
+func listExistingPaths(treeRoot string, respectGitIgnore bool) ([]string, error) {
+	var gitIgnore gitignore.GitIgnore
+	if respectGitIgnore {
+		var err error
+		if gitIgnore, err = newGitIgnore(treeRoot); err != nil {
+			return nil, err
+		}
+	}
+	var paths []string
+	err := filepath.WalkDir(treeRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == gitDirectory {
+			return filepath.SkipDir
+		}
+		if path == treeRoot {
+			return nil
+		}
+		if gitIgnore != nil && gitIgnore.Absolute(path, d.IsDir()) != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relativePath, err := filepath.Rel(treeRoot, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(relativePath))
+		return nil
+	})
+	return paths, err
+}
+
+// suggest ranks the candidates that the dead link may have been meant to point to, and returns the best ones.
+func suggest(deadLink string, candidates []string) []Suggestion {
+	var found []Suggestion
+	for _, candidate := range candidates {
+		reason, distance, matches := suggestionReason(deadLink, candidate)
+		if !matches {
+			continue
+		}
+		found = append(found, Suggestion{Path: candidate, Reason: reason, Distance: distance})
+	}
+
+	slices.SortFunc(found, func(a, b Suggestion) int {
+		if rankA, rankB := reasonRanks[a.Reason], reasonRanks[b.Reason]; rankA != rankB {
+			return rankA - rankB
+		}
+		if a.Distance != b.Distance {
+			return a.Distance - b.Distance
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	if len(found) > maxSuggestions {
+		found = found[:maxSuggestions]
+	}
+	return found
+}
+
+// suggestionReason tells if the candidate is a likely target for the dead link, why, and how far apart they are.
+func suggestionReason(deadLink string, candidate string) (SuggestionReason, int, bool) {
+	if candidate == deadLink {
+		return "", 0, false
+	}
+	if strings.EqualFold(candidate, deadLink) {
+		return CaseMismatch, editDistance(deadLink, candidate), true
+	}
+	if candidate == deadLink+".md" || candidate+".md" == deadLink {
+		return ExtensionMismatch, editDistance(deadLink, candidate), true
+	}
+	if filepath.Base(candidate) == filepath.Base(deadLink) {
+		return SameBasename, editDistance(deadLink, candidate), true
+	}
+	// Computing the edit distance is costly: skip candidates that can't be close enough.
+	lengthDifference := len(candidate) - len(deadLink)
+	if lengthDifference > maxSuggestionDistance || lengthDifference < -maxSuggestionDistance {
+		return "", 0, false
+	}
+	// Short links would otherwise be similar to anything short
+	maxDistance := min(maxSuggestionDistance, len(deadLink)/5)
+	if distance := editDistance(deadLink, candidate); distance <= maxDistance {
+		return SimilarPath, distance, true
+	}
+	return "", 0, false
+}
+
+// editDistance computes the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			substitution := previous[j-1]
+			if runesA[i-1] != runesB[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runesB)]
+}
+
+// NewSuggestionRelocator returns a relocator that relies on the suggestions of the passed reports.
+// A dead link is only relocated if its best suggestion stands out from the others.
+func NewSuggestionRelocator(reports map[string]NodeReport) Relocator {
+	best := make(map[string]string)
+	for _, report := range reports {
+		for deadLink, suggestions := range report.Suggestions {
+			if len(suggestions) == 1 ||
+				(len(suggestions) > 1 && (suggestions[0].Reason != suggestions[1].Reason ||
+					suggestions[0].Distance < suggestions[1].Distance)) {
+				best[deadLink] = suggestions[0].Path
+			}
+		}
+	}
+	return func(deadLink string) (string, bool) {
+		path, found := best[deadLink]
+		return path, found
+	}
+}
+
+// DidYouMean formats the passed suggestions for a dead link to be appended to a message about it,
+// as in `docs/instal.md (did you mean docs/install.md?)`. It returns an empty string if there are none.
+func DidYouMean(suggestions []Suggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var paths []string
+	for _, suggestion := range suggestions {
+		paths = append(paths, suggestion.Path)
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(paths, ", "))
+}
@@ -0,0 +1,99 @@
+package checkdoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("docs/install.md", "docs/install.md"))
+	assert.Equal(t, 1, editDistance("docs/instal.md", "docs/install.md"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 4, editDistance("", "docs"))
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{
+		"docs",
+		"docs/install.md",
+		"docs/Install.md",
+		"docs/CHANGELOG.md",
+		"guides/install.md",
+		"other/install.md",
+		"docs/uninstall.md",
+		"a.md",
+	}
+
+	assert.Equal(t, []Suggestion{
+		{Path: "docs/install.md", Reason: SimilarPath, Distance: 1},
+		{Path: "docs/Install.md", Reason: SimilarPath, Distance: 2},
+	}, suggest("docs/instal.md", candidates))
+
+	assert.Equal(t, []Suggestion{
+		{Path: "docs/Install.md", Reason: CaseMismatch, Distance: 1},
+		{Path: "guides/install.md", Reason: SameBasename, Distance: 5},
+		{Path: "other/install.md", Reason: SameBasename, Distance: 5},
+	}, suggest("docs/install.md", candidates[2:]), "At most three suggestions, the most convincing first")
+
+	assert.Equal(t, []Suggestion{{Path: "docs/CHANGELOG.md", Reason: ExtensionMismatch, Distance: 3}},
+		suggest("docs/CHANGELOG", candidates))
+
+	assert.Empty(t, suggest("b.md", candidates), "Short links should not be similar to everything")
+	assert.Empty(t, suggest("something/completely/different.md", candidates))
+}
+
+func TestSuggestTargets(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# Root\n\n[install](docs/instal.md) [ok](docs/install.md) [nothing](nowhere/at/all.md)\n" +
+			"[setup](docs/setup.md)\n",
+		"docs/install.md": "# Install\n",
+		"docs/setup.md~":  "# Setup backup\n",
+		".gitignore":      "*~\n",
+	})
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	assert.Nil(t, reports["README.md"].Suggestions, "Suggestions should only be added on demand")
+	SuggestTargets(treeRoot, reports, true)
+	assert.Equal(t, map[string][]Suggestion{
+		"docs/instal.md": {{Path: "docs/install.md", Reason: SimilarPath, Distance: 1}},
+	}, reports["README.md"].Suggestions)
+	assert.Nil(t, reports["docs/install.md"].Suggestions)
+
+	relocator := NewSuggestionRelocator(reports)
+	newPath, found := relocator("docs/instal.md")
+	assert.True(t, found)
+	assert.Equal(t, "docs/install.md", newPath)
+	_, found = relocator("nowhere/at/all.md")
+	assert.False(t, found)
+
+	SuggestTargets(treeRoot, reports, false)
+	assert.Equal(t, []Suggestion{{Path: "docs/setup.md~", Reason: SimilarPath, Distance: 1}},
+		reports["README.md"].Suggestions["docs/setup.md"], "Ignored files should only be suggested if git is not respected")
+}
+
+func TestSuggestionRelocatorAmbiguous(t *testing.T) {
+	reports := map[string]NodeReport{
+		"README.md": {Suggestions: map[string][]Suggestion{
+			"docs/a.md": {
+				{Path: "x/a.md", Reason: SameBasename, Distance: 5},
+				{Path: "y/a.md", Reason: SameBasename, Distance: 5},
+			},
+			"docs/b.md": {
+				{Path: "docs/B.md", Reason: CaseMismatch, Distance: 1},
+				{Path: "y/b.md", Reason: SameBasename, Distance: 5},
+			},
+		}},
+	}
+	relocator := NewSuggestionRelocator(reports)
+
+	_, found := relocator("docs/a.md")
+	assert.False(t, found, "No suggestion stands out")
+	newPath, found := relocator("docs/b.md")
+	assert.True(t, found)
+	assert.Equal(t, "docs/B.md", newPath)
+}
@@ -5,36 +5,46 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // NodeReport contains some information about the quality of a node
 type NodeReport struct {
-	Node      LinkGraphNode // The underlying node
-	DeadLinks []string      // (local) dead links that this node contain
-	IsOrphan  bool          // Does anything point to this node
+	Node            LinkGraphNode           // The underlying node
+	DeadLinks       []string                // (local) dead links that this node contain
+	DirectoryLinks  []string                // (local) links to directories without index, if directory links were checked
+	ImplicitLinks   []ImplicitLink          // (local) links relying on implicit resolution, if explicit links were required
+	LinkStyleIssues []LinkStyleIssue        // (local) links not following the link style, if one was checked
+	DeadImageLinks  []string                // (local) images embedded in this node that do not exist
+	InvalidImages   []InvalidImage          // (local) images that exist but failed the content checks, if any were run
+	ReferenceIssues []ReferenceIssue        // undefined, unused or duplicate references in this node
+	Suggestions     map[string][]Suggestion // for dead links and images, paths that may have been meant instead
+	TooDeep         *DepthViolation         // set if the node is further away from the root documents than allowed
+	IsOrphan        bool                    // Nothing points to this node, which is not a root document either
 }
 
-// TODO the whole package needs a little rewrite to use some form of object that contains the config
-//  and state (parameters, etc) and on which the methods are called.
-
 // TODO if we ever want to do more fancy things, this part of the lib deserves to be rewritten to use
 // a graph library, something like gonum/graph.
 
 // ValidateReports the passed report map. Currently, this checks that:
-//   - there are no orphan pages (without inbound links), except for the root README.md
+//   - there are no orphan pages (without inbound links), except for the root documents
 //   - internal links point to existing things (either files, directories or other readmes)
+//   - links to directories lead to an implicit index, if the directory links were checked
+//   - links point to documents explicitly, without relying on implicit resolution, if this was checked
+//   - links follow the link style, if one was checked
+//   - embedded images point to existing files, that passed the image checks if any were run
+//   - reference-style links are defined, definitions are used and not defined twice
+//   - documents are not too far away from the root documents, if a maximum depth was checked
 //
 // This method returns 'true' if no issues where found, and false otherwise
 func ValidateReports(reports map[string]NodeReport) bool {
 	// TODO consider adding rules allowing for things like CHANGELOG files not to be linked to
-	// TODO add a flag to tolerate or refuse things like README (ie, force the extension)
 	var isValid = true
 	slog.Info("Checking for orphaned documents...")
 	var orphans []string
 	for path, report := range reports {
-		// TODO specify the root file via an option
-		if report.IsOrphan && path != "README.md" {
+		if report.IsOrphan {
 			orphans = append(orphans, path)
 			isValid = false
 		}
@@ -52,6 +62,72 @@ func ValidateReports(reports map[string]NodeReport) bool {
 
 	logDeadLinks(withDeadLinks)
 
+	slog.Info("Checking for links to directories without index...")
+	var withDirectoryLinks []NodeReport
+	for _, report := range reports {
+		if len(report.DirectoryLinks) != 0 {
+			withDirectoryLinks = append(withDirectoryLinks, report)
+			isValid = false
+		}
+	}
+
+	logDirectoryLinks(withDirectoryLinks)
+
+	slog.Info("Checking for implicit links...")
+	var withImplicitLinks []NodeReport
+	for _, report := range reports {
+		if len(report.ImplicitLinks) != 0 {
+			withImplicitLinks = append(withImplicitLinks, report)
+			isValid = false
+		}
+	}
+
+	logImplicitLinks(withImplicitLinks)
+
+	slog.Info("Checking for link style issues...")
+	var withLinkStyleIssues []NodeReport
+	for _, report := range reports {
+		if len(report.LinkStyleIssues) != 0 {
+			withLinkStyleIssues = append(withLinkStyleIssues, report)
+			isValid = false
+		}
+	}
+
+	logLinkStyleIssues(withLinkStyleIssues)
+
+	slog.Info("Checking for broken images...")
+	var withBrokenImages []NodeReport
+	for _, report := range reports {
+		if len(report.DeadImageLinks) != 0 || len(report.InvalidImages) != 0 {
+			withBrokenImages = append(withBrokenImages, report)
+			isValid = false
+		}
+	}
+
+	logBrokenImages(withBrokenImages)
+
+	slog.Info("Checking for reference issues...")
+	var withReferenceIssues []NodeReport
+	for _, report := range reports {
+		if len(report.ReferenceIssues) != 0 {
+			withReferenceIssues = append(withReferenceIssues, report)
+			isValid = false
+		}
+	}
+
+	logReferenceIssues(withReferenceIssues)
+
+	slog.Info("Checking for documents too deep...")
+	var tooDeep []NodeReport
+	for _, report := range reports {
+		if report.TooDeep != nil {
+			tooDeep = append(tooDeep, report)
+			isValid = false
+		}
+	}
+
+	logTooDeep(tooDeep)
+
 	return isValid
 }
 
@@ -75,28 +151,122 @@ func logDeadLinks(withDeadLinks []NodeReport) {
 	for _, invalid := range withDeadLinks {
 		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
 		for _, deadLink := range invalid.DeadLinks {
-			slog.Error(fmt.Sprintf("\t\t%s", deadLink))
+			slog.Error(fmt.Sprintf("\t\t%s%s", deadLink, DidYouMean(invalid.Suggestions[deadLink])))
+		}
+	}
+}
+
+func logDirectoryLinks(withDirectoryLinks []NodeReport) {
+	if len(withDirectoryLinks) == 0 {
+		slog.Info("No links to directories without index found.")
+		return
+	}
+	slog.Error("Located some files with links to directories without index:")
+	for _, invalid := range withDirectoryLinks {
+		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
+		for _, directory := range invalid.DirectoryLinks {
+			slog.Error(fmt.Sprintf("\t\t%s (directory-without-index)", directory))
 		}
 	}
 }
 
+func logImplicitLinks(withImplicitLinks []NodeReport) {
+	if len(withImplicitLinks) == 0 {
+		slog.Info("No implicit links found.")
+		return
+	}
+	slog.Error("Located some files with implicit links:")
+	for _, invalid := range withImplicitLinks {
+		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
+		for _, implicitLink := range invalid.ImplicitLinks {
+			slog.Error(fmt.Sprintf("\t\t%s (resolves to %s)", implicitLink.Link, implicitLink.Target))
+		}
+	}
+}
+
+func logLinkStyleIssues(withLinkStyleIssues []NodeReport) {
+	if len(withLinkStyleIssues) == 0 {
+		slog.Info("No link style issues found.")
+		return
+	}
+	slog.Error("Located some files with link style issues:")
+	for _, invalid := range withLinkStyleIssues {
+		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
+		for _, issue := range invalid.LinkStyleIssues {
+			suggestion := ""
+			if issue.Suggestion != "" {
+				suggestion = fmt.Sprintf(", use %s instead", issue.Suggestion)
+			}
+			slog.Error(fmt.Sprintf("\t\t%s (%s%s)", issue.Destination, issue.Reason, suggestion))
+		}
+	}
+}
+
+func logBrokenImages(withBrokenImages []NodeReport) {
+	if len(withBrokenImages) == 0 {
+		slog.Info("No broken images found.")
+		return
+	}
+	slog.Error("Located some files with broken images:")
+	for _, invalid := range withBrokenImages {
+		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
+		for _, deadImage := range invalid.DeadImageLinks {
+			slog.Error(fmt.Sprintf("\t\t%s (not found)%s", deadImage, DidYouMean(invalid.Suggestions[deadImage])))
+		}
+		for _, invalidImage := range invalid.InvalidImages {
+			slog.Error(fmt.Sprintf("\t\t%s (%s)", invalidImage.Path, invalidImage.Reason))
+		}
+	}
+}
+
+func logReferenceIssues(withReferenceIssues []NodeReport) {
+	if len(withReferenceIssues) == 0 {
+		slog.Info("No reference issues found.")
+		return
+	}
+	slog.Error("Located some files with reference issues:")
+	for _, invalid := range withReferenceIssues {
+		slog.Error(fmt.Sprintf("\t%s", invalid.Node.RelativePath))
+		for _, issue := range invalid.ReferenceIssues {
+			slog.Error(fmt.Sprintf("\t\tline %d: [%s] (%s)", issue.Line, issue.Label, issue.Kind))
+		}
+	}
+}
+
+func logTooDeep(tooDeep []NodeReport) {
+	if len(tooDeep) == 0 {
+		slog.Info("No documents too deep found.")
+		return
+	}
+	slog.Error("Located some documents too deep:")
+	for _, invalid := range tooDeep {
+		slog.Error(fmt.Sprintf("\t%s (depth %d: %s)", invalid.Node.RelativePath, invalid.TooDeep.Depth,
+			strings.Join(invalid.TooDeep.Path, " -> ")))
+	}
+}
+
 // BuildReport will run through the passed nodes, using the specified root to run its checks, and build a report for each node
-// that will be container within the returned map
-func BuildReport(treeRoot string, nodes []LinkGraphNode, implicitIndexes []string) map[string]NodeReport {
+// that will be container within the returned map. The root documents, relative to the root, are where readers start
+// browsing: they are not expected to be linked to.
+func BuildReport(treeRoot string, nodes []LinkGraphNode, implicitIndexes []string, rootDocuments []string) map[string]NodeReport {
 	rawPathSet := BuildLocalPathSet(nodes)
 
 	resolvedPaths := resolveImplicitPaths(treeRoot, implicitIndexes, rawPathSet)
 
 	deadLinks := buildDeadLinkReport(resolvedPaths, nodes)
-	orphans := buildOrphanReport(resolvedPaths, nodes)
+	deadImageLinks := buildDeadImageReport(resolvedPaths, nodes)
+	referenceIssues := buildReferenceReport(nodes)
+	orphans := buildOrphanReport(resolvedPaths, nodes, rootDocuments)
 
 	nodeReports := make(map[string]NodeReport)
 
 	for _, node := range nodes {
 		nodeReports[node.RelativePath] = NodeReport{
-			Node:      node,
-			DeadLinks: deadLinks[node.RelativePath],
-			IsOrphan:  orphans[node.RelativePath],
+			Node:            node,
+			DeadLinks:       deadLinks[node.RelativePath],
+			DeadImageLinks:  deadImageLinks[node.RelativePath],
+			ReferenceIssues: referenceIssues[node.RelativePath],
+			IsOrphan:        orphans[node.RelativePath],
 		}
 	}
 
@@ -104,12 +274,12 @@ func BuildReport(treeRoot string, nodes []LinkGraphNode, implicitIndexes []strin
 }
 
 // buildOrphanReport looks up all passed nodes in the specified pathSet, and returns a map telling if they are present in it,
-// ie, have or have not been linked to.
+// ie, have or have not been linked to. Root documents are never orphans.
 // Note:
 //   - this won't catch any node that linked to itself, but this seems like an acceptable corner case, as it
 //     would look pretty obvious in a document anyway.
 //   - resolvedPathSet is expected to contain only paths to files, not directories.
-func buildOrphanReport(resolvedPathSet map[string]bool, nodes []LinkGraphNode) map[string]bool {
+func buildOrphanReport(resolvedPathSet map[string]bool, nodes []LinkGraphNode, rootDocuments []string) map[string]bool {
 	orphanReport := make(map[string]bool)
 	// resolvedPathSet contains an exhaustive set of all existing local links, relative to the tree root.
 	// We check all nodes against it to check if they were pointed to from somewhere.
@@ -117,7 +287,7 @@ func buildOrphanReport(resolvedPathSet map[string]bool, nodes []LinkGraphNode) m
 		// Do a lookup, check if the value is present
 		_, present := resolvedPathSet[node.RelativePath]
 		// ... and set the value in the report accordingly: if it is present, it's not an orphan
-		orphanReport[node.RelativePath] = !present
+		orphanReport[node.RelativePath] = !present && !slices.Contains(rootDocuments, node.RelativePath)
 	}
 	return orphanReport
 }
@@ -129,26 +299,44 @@ func buildDeadLinkReport(resolvedPathSet map[string]bool, nodes []LinkGraphNode)
 	toRet := make(map[string][]string)
 
 	for _, node := range nodes {
-		deadLinks := []string{}
-		for _, link := range node.NormalizedLocalRelativeLinks {
-			if _, present := resolvedPathSet[link]; !present {
-				deadLinks = append(deadLinks, link)
-			}
-		}
 		// Keep track of the dead links for that node, identified by its relative path.
-		toRet[node.RelativePath] = deadLinks
+		toRet[node.RelativePath] = findDeadLinks(resolvedPathSet, node.NormalizedLocalRelativeLinks)
 	}
 
 	return toRet
 }
 
-// BuildLocalPathSet returns a set of all local links found in the passed nodes.
+// buildDeadImageReport is the counterpart of buildDeadLinkReport for images embedded in each passed graph node.
+func buildDeadImageReport(resolvedPathSet map[string]bool, nodes []LinkGraphNode) map[string][]string {
+	toRet := make(map[string][]string)
+
+	for _, node := range nodes {
+		toRet[node.RelativePath] = findDeadLinks(resolvedPathSet, node.NormalizedLocalImageLinks)
+	}
+
+	return toRet
+}
+
+func findDeadLinks(resolvedPathSet map[string]bool, links []string) []string {
+	deadLinks := []string{}
+	for _, link := range links {
+		if _, present := resolvedPathSet[link]; !present {
+			deadLinks = append(deadLinks, link)
+		}
+	}
+	return deadLinks
+}
+
+// BuildLocalPathSet returns a set of all local links found in the passed nodes, including embedded images.
 func BuildLocalPathSet(nodes []LinkGraphNode) map[string]bool {
 	toRet := make(map[string]bool)
 	for _, node := range nodes {
 		for _, relativePath := range node.NormalizedLocalRelativeLinks {
 			toRet[relativePath] = false
 		}
+		for _, relativePath := range node.NormalizedLocalImageLinks {
+			toRet[relativePath] = false
+		}
 	}
 	return toRet
 }
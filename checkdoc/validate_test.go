@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
 )
 
 func TestValidateSimple(t *testing.T) {
@@ -13,7 +15,7 @@ func TestValidateSimple(t *testing.T) {
 	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, extensions, false)
 	assert.NoError(t, err)
 
-	reports := BuildReport(treeRoot, nodes, []string{})
+	reports := BuildReport(treeRoot, nodes, []string{}, []string{"README.md"})
 	assert.True(t, ValidateReports(reports), "The specified directory is expected to be valid.")
 }
 
@@ -33,7 +35,7 @@ func TestValidateFail(t *testing.T) {
 	nodes, err := BuildLinkGraphNodes(treeRoot, baseNames, extensions, false)
 	assert.NoError(t, err)
 
-	reports := BuildReport(treeRoot, nodes, implicitIndexes)
+	reports := BuildReport(treeRoot, nodes, implicitIndexes, []string{"README.md"})
 	assert.False(t, ValidateReports(reports), "The complete test setup is expected to fail validation")
 }
 
@@ -45,7 +47,7 @@ func TestBuildReport(t *testing.T) {
 	nodes, err := BuildLinkGraphNodes(treeRoot, baseNames, extensions, false)
 	assert.NoError(t, err)
 
-	reports := BuildReport(treeRoot, nodes, implicitIndexes)
+	reports := BuildReport(treeRoot, nodes, implicitIndexes, nil)
 
 	assert.Equal(t, 7, len(reports))
 	// Quick sanity check...
@@ -77,8 +79,8 @@ func TestBuildReport(t *testing.T) {
 }
 
 func TestBuildPathSet(t *testing.T) {
-	nodeA := LinkGraphNode{"some/path", nil, []string{"path/a", "path/b"}}
-	nodeB := LinkGraphNode{"some/path", nil, []string{"path/b", "path/c"}}
+	nodeA := LinkGraphNode{RelativePath: "some/path", NormalizedLocalRelativeLinks: []string{"path/a", "path/b"}}
+	nodeB := LinkGraphNode{RelativePath: "some/path", NormalizedLocalRelativeLinks: []string{"path/b", "path/c"}}
 
 	pathSet := BuildLocalPathSet([]LinkGraphNode{nodeA, nodeB})
 	expected := map[string]bool{
@@ -167,14 +169,14 @@ func TestResolveImplicitPathsTestData(t *testing.T) {
 }
 
 func TestSearchOrphansAllOrphans(t *testing.T) {
-	nodeA := LinkGraphNode{"README.md", nil, []string{"non-existing"}}
-	nodeB := LinkGraphNode{"sub-dir-a/README.md", nil, []string{}}
+	nodeA := LinkGraphNode{RelativePath: "README.md", NormalizedLocalRelativeLinks: []string{"non-existing"}}
+	nodeB := LinkGraphNode{RelativePath: "sub-dir-a/README.md", NormalizedLocalRelativeLinks: []string{}}
 
 	pathSet := map[string]bool{
 		"non-existing": false, // something non existing, thus not implicit
 	}
 
-	orphans := buildOrphanReport(pathSet, []LinkGraphNode{nodeA, nodeB})
+	orphans := buildOrphanReport(pathSet, []LinkGraphNode{nodeA, nodeB}, nil)
 
 	assert.Equal(t, map[string]bool{
 		"README.md":           true, // nothing should point to the root node
@@ -182,17 +184,33 @@ func TestSearchOrphansAllOrphans(t *testing.T) {
 	}, orphans, "expected to find only orphans")
 }
 
+func TestSearchOrphansRootDocuments(t *testing.T) {
+	nodeA := LinkGraphNode{RelativePath: "index.md", NormalizedLocalRelativeLinks: []string{"sub-dir-a/README.md"}}
+	nodeB := LinkGraphNode{RelativePath: "sub-dir-a/README.md", NormalizedLocalRelativeLinks: []string{}}
+	nodeC := LinkGraphNode{RelativePath: "README.md", NormalizedLocalRelativeLinks: []string{}}
+
+	pathSet := map[string]bool{"sub-dir-a/README.md": false}
+
+	orphans := buildOrphanReport(pathSet, []LinkGraphNode{nodeA, nodeB, nodeC}, []string{"index.md"})
+
+	assert.Equal(t, map[string]bool{
+		"index.md":            false, // a root document needs no link to it
+		"sub-dir-a/README.md": false,
+		"README.md":           true, // not a root document
+	}, orphans)
+}
+
 func TestSearchOrphansOnlyRootOrphan(t *testing.T) {
-	nodeA := LinkGraphNode{"README.md", nil,
-		[]string{"non-existing", "sub-dir-a/README.md"}}
-	nodeB := LinkGraphNode{"sub-dir-a/README.md", nil, []string{}}
+	nodeA := LinkGraphNode{RelativePath: "README.md",
+		NormalizedLocalRelativeLinks: []string{"non-existing", "sub-dir-a/README.md"}}
+	nodeB := LinkGraphNode{RelativePath: "sub-dir-a/README.md", NormalizedLocalRelativeLinks: []string{}}
 
 	pathSet := map[string]bool{
 		"non-existing":        false, // non implicit link
 		"sub-dir-a/README.md": false, // non implicit link
 	}
 
-	orphans := buildOrphanReport(pathSet, []LinkGraphNode{nodeA, nodeB})
+	orphans := buildOrphanReport(pathSet, []LinkGraphNode{nodeA, nodeB}, nil)
 
 	assert.Equal(t, map[string]bool{
 		"README.md":           true,  // nothing should point to the root node
@@ -207,10 +225,10 @@ func TestBuildDeadLinkReport(t *testing.T) {
 		"sub-dir-a/README": true,  // points to an index file in a dir, implicitly resolved
 	}
 
-	nodeA := LinkGraphNode{"README.md", nil,
-		[]string{"sub-dir-a"}}
-	nodeB := LinkGraphNode{"sub-dir-a/README", nil,
-		[]string{"README.md", "sub-dir-c", "sub-dir-c/some-file"}}
+	nodeA := LinkGraphNode{RelativePath: "README.md",
+		NormalizedLocalRelativeLinks: []string{"sub-dir-a"}}
+	nodeB := LinkGraphNode{RelativePath: "sub-dir-a/README",
+		NormalizedLocalRelativeLinks: []string{"README.md", "sub-dir-c", "sub-dir-c/some-file"}}
 
 	deadLinkReport := buildDeadLinkReport(pathSet, []LinkGraphNode{nodeA, nodeB})
 	assert.Equal(t, map[string][]string{
@@ -237,3 +255,19 @@ func TestEnsureDirectoriesEndWithSlash(t *testing.T) {
 		"sub-dir-a/README": false,
 	}, processed)
 }
+
+func TestBuildReportHTMLLinks(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md": "# HTML\n\n<p align=\"center\">\n  <a href=\"docs/\"><img src=\"img/logo.png\"></a>\n</p>\n\n" +
+			"See <a href=\"docs/gone.md\">this</a>.\n",
+		"docs/README.md": "# Docs\n",
+	})
+
+	nodes, err := BuildLinkGraphNodes(treeRoot, []string{}, []string{".md"}, false)
+	assert.NoError(t, err)
+
+	reports := BuildReport(treeRoot, nodes, []string{"README.md"}, []string{"README.md"})
+	assert.False(t, reports["docs/README.md"].IsOrphan, "Links in HTML should count as inbound links")
+	assert.Equal(t, []string{"docs/gone.md"}, reports["README.md"].DeadLinks)
+	assert.Equal(t, []string{"img/logo.png"}, reports["README.md"].DeadImageLinks)
+}
@@ -0,0 +1,263 @@
+package checkdoc
+
+//revive:disable:flag-parameter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// fileStamp is what tells that a file changed between two walks of the tree.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// IncrementalGraph keeps the link graph nodes of a tree up to date, re-parsing only the documents that changed.
+// Changes are detected by comparing the modification time and size of all files below the root, except in
+// the .git directory, between calls to Update. Documents can also be given an in-memory source, such as
+// the unsaved content of an editor, that takes precedence over their file.
+//
+// Only parsing is incremental: each Update still walks the whole tree, including the directories ignored by git,
+// and the checks that depend on the whole graph, such as orphans, need to run on all the nodes again.
+// This is fine for the documentation of a repository, less so for trees with very large ignored directories.
+type IncrementalGraph struct {
+	treeRoot         string
+	baseNames        []string
+	fileExtensions   []string
+	respectGitIgnore bool
+	parser           markdown.Parser
+
+	stamps   map[string]fileStamp      // All files and directories below the root, by absolute path
+	nodes    map[string]*LinkGraphNode // The documents, by absolute path
+	sources  map[string][]byte         // The in-memory sources of documents, by absolute path
+	failures map[string]error          // Why documents could not be parsed at their last change, by absolute path
+}
+
+// NewIncrementalGraph builds the link graph nodes of a tree like BuildLinkGraphNodesWithParser,
+// and returns them to be kept up to date with Update.
+func NewIncrementalGraph(
+	treeRoot string,
+	baseNames []string,
+	fileExtensions []string,
+	respectGitIgnore bool,
+	parser markdown.Parser,
+) (*IncrementalGraph, error) {
+	if len(baseNames) == 0 && len(fileExtensions) == 0 {
+		return nil, fmt.Errorf("need to specify at least one base name or extension")
+	}
+	if !filepath.IsAbs(treeRoot) {
+		return nil, fmt.Errorf("treeRoot must be absolute, was: %s", treeRoot)
+	}
+	graph := &IncrementalGraph{
+		treeRoot:         treeRoot,
+		baseNames:        baseNames,
+		fileExtensions:   fileExtensions,
+		respectGitIgnore: respectGitIgnore,
+		parser:           parser,
+		nodes:            make(map[string]*LinkGraphNode),
+		sources:          make(map[string][]byte),
+		failures:         make(map[string]error),
+	}
+	if _, _, err := graph.Update(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// Nodes returns the current link graph nodes, sorted by path.
+func (g *IncrementalGraph) Nodes() []LinkGraphNode {
+	var nodes []LinkGraphNode
+	for _, absPath := range sortedKeys(g.nodes) {
+		nodes = append(nodes, *g.nodes[absPath])
+	}
+	return nodes
+}
+
+// Node returns the node of the document at absPath, if it is one.
+func (g *IncrementalGraph) Node(absPath string) (LinkGraphNode, bool) {
+	node, found := g.nodes[absPath]
+	if !found {
+		return LinkGraphNode{}, false
+	}
+	return *node, true
+}
+
+// Failures returns why documents could not be parsed when they last changed, by path relative to the root.
+// These documents keep the node of their last successful parsing, if any, until they are parsed again.
+func (g *IncrementalGraph) Failures() map[string]error {
+	failures := make(map[string]error)
+	for absPath, err := range g.failures {
+		failures[g.relativePath(absPath)] = err
+	}
+	return failures
+}
+
+// Update walks the tree for changes, and re-parses the documents that were added or modified.
+// It tells if anything changed in the tree at all, since findings also depend on files other than documents,
+// and returns the documents that were re-parsed or removed, relative to the root and sorted.
+// Documents are parsed independently: one failing to parse is returned as well, and recorded in Failures.
+// An error is only returned if the tree could not be walked, in which case nothing is updated.
+func (g *IncrementalGraph) Update() (bool, []string, error) {
+	stamps, err := walkStamps(g.treeRoot)
+	if err != nil {
+		return false, nil, err
+	}
+	if maps.Equal(stamps, g.stamps) {
+		return false, nil, nil
+	}
+
+	var candidates []string
+	for absPath, stamp := range stamps {
+		if !stamp.isDir && g.isDocument(absPath) {
+			candidates = append(candidates, absPath)
+		}
+	}
+	documents, err := filterGitIgnored(g.treeRoot, candidates, g.respectGitIgnore)
+	if err != nil {
+		return false, nil, err
+	}
+
+	isDocument := make(map[string]bool)
+	var toParse []string
+	for _, absPath := range documents {
+		isDocument[absPath] = true
+		_, known := g.nodes[absPath]
+		_, failed := g.failures[absPath]
+		if (!known && !failed) || stamps[absPath] != g.stamps[absPath] {
+			toParse = append(toParse, absPath)
+		}
+	}
+	var changed []string
+	for _, absPath := range sortedKeys(g.nodes) {
+		if _, inMemory := g.sources[absPath]; !isDocument[absPath] && !inMemory {
+			changed = append(changed, g.nodes[absPath].RelativePath)
+			delete(g.nodes, absPath)
+		}
+	}
+	for absPath := range g.failures {
+		if !isDocument[absPath] {
+			delete(g.failures, absPath)
+		}
+	}
+	for _, absPath := range toParse {
+		g.reparse(absPath)
+		changed = append(changed, g.relativePath(absPath))
+	}
+	g.stamps = stamps
+	slices.Sort(changed)
+	return true, changed, nil
+}
+
+// SetSource sets the in-memory source of the document at absPath, which then takes precedence over the document's
+// file until ClearSource is called, and parses it. The document need not exist on disk.
+// If the source fails to parse, the failure is recorded in Failures, and the document keeps its last node,
+// or gets an empty one: its source is always the one passed.
+func (g *IncrementalGraph) SetSource(absPath string, source []byte) error {
+	if !g.isDocument(absPath) || !strings.HasPrefix(absPath, strings.TrimSuffix(g.treeRoot, "/")+"/") {
+		return fmt.Errorf("%s is not a document within the tree root %s", absPath, g.treeRoot)
+	}
+	g.sources[absPath] = source
+	g.reparse(absPath)
+	if _, found := g.nodes[absPath]; !found {
+		g.nodes[absPath] = &LinkGraphNode{RelativePath: g.relativePath(absPath)}
+	}
+	return nil
+}
+
+// reparse parses the document at absPath again, keeping its last node and recording the failure if it fails.
+func (g *IncrementalGraph) reparse(absPath string) {
+	node, err := g.parse(absPath)
+	if err != nil {
+		g.failures[absPath] = err
+		return
+	}
+	delete(g.failures, absPath)
+	g.nodes[absPath] = &node
+}
+
+// relativePath returns the path of absPath relative to the root.
+func (g *IncrementalGraph) relativePath(absPath string) string {
+	return strings.TrimPrefix(absPath, strings.TrimSuffix(g.treeRoot, "/")+"/")
+}
+
+// ClearSource drops the in-memory source of the document at absPath, which is parsed from its file again,
+// if it still is a document.
+func (g *IncrementalGraph) ClearSource(absPath string) {
+	if _, inMemory := g.sources[absPath]; !inMemory {
+		return
+	}
+	delete(g.sources, absPath)
+	_, onDisk := g.stamps[absPath]
+	if _, err := os.Stat(absPath); !onDisk || errors.Is(err, fs.ErrNotExist) {
+		delete(g.nodes, absPath)
+		delete(g.failures, absPath)
+		return
+	}
+	g.reparse(absPath)
+}
+
+// Source returns the source of the document at absPath: its in-memory source if it has one,
+// or else the content of its file.
+func (g *IncrementalGraph) Source(absPath string) ([]byte, error) {
+	if source, inMemory := g.sources[absPath]; inMemory {
+		return source, nil
+	}
+	return os.ReadFile(absPath)
+}
+
+// parse parses the document at absPath into a node.
+func (g *IncrementalGraph) parse(absPath string) (LinkGraphNode, error) {
+	source, err := g.Source(absPath)
+	if err != nil {
+		return LinkGraphNode{}, err
+	}
+	document, err := markdown.ParseSource(g.parser, source)
+	if err := warnInvalidFrontMatter(absPath, err); err != nil {
+		return LinkGraphNode{}, fmt.Errorf("failed to parse markdown file %s: %w", absPath, err)
+	}
+	sanitizedRoot := strings.TrimSuffix(g.treeRoot, "/") + "/"
+	return buildGraphNode(sanitizedRoot, &parsedDocument{AbsPath: absPath, Source: source, Document: document})
+}
+
+// isDocument tells if the passed path is a document by its base name or extension.
+func (g *IncrementalGraph) isDocument(absPath string) bool {
+	return slices.Contains(g.baseNames, filepath.Base(absPath)) || slices.Contains(g.fileExtensions, filepath.Ext(absPath))
+}
+
+// walkStamps returns the stamps of all files and directories below treeRoot, by absolute path.
+func walkStamps(treeRoot string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(treeRoot, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed while walking: the next walk will tell
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == gitDirectory {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size(), isDir: d.IsDir()}
+		return nil
+	})
+	return stamps, err
+}
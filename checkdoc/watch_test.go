@@ -0,0 +1,123 @@
+package checkdoc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+func TestIncrementalGraph(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "[install](docs/install.md)\n",
+		"docs/install.md": "[run](../tools/run.sh)\n",
+		"docs/faq.md":     "# FAQ\n",
+		"tools/run.sh":    "#!/bin/sh\n",
+	})
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	assert.NoError(t, err)
+
+	graph, err := NewIncrementalGraph(treeRoot, nil, []string{".md"}, false, parser)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "docs/faq.md", "docs/install.md"}, nodePaths(graph.Nodes()))
+
+	changed, documents, err := graph.Update()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, documents)
+
+	// A change to a file other than a document is a change, but nothing needs to be parsed again
+	future := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(treeRoot, "tools/run.sh"), future, future))
+	changed, documents, err = graph.Update()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, documents)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(treeRoot, "docs/install.md"), []byte("[faq](faq.md)\n"), 0644))
+	assert.NoError(t, os.Chtimes(filepath.Join(treeRoot, "docs/install.md"), future, future))
+	assert.NoError(t, os.Remove(filepath.Join(treeRoot, "docs/faq.md")))
+	assert.NoError(t, os.WriteFile(filepath.Join(treeRoot, "docs/new.md"), []byte("# New\n"), 0644))
+	changed, documents, err = graph.Update()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"docs/faq.md", "docs/install.md", "docs/new.md"}, documents)
+
+	nodes := graph.Nodes()
+	assert.Equal(t, []string{"README.md", "docs/install.md", "docs/new.md"}, nodePaths(nodes))
+	assert.Equal(t, []string{"docs/faq.md"}, nodes[1].NormalizedLocalRelativeLinks)
+}
+
+func TestIncrementalGraphInvalidFrontMatter(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{"README.md": "# Root\n"})
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	assert.NoError(t, err)
+	graph, err := NewIncrementalGraph(treeRoot, nil, []string{".md"}, false, parser)
+	assert.NoError(t, err)
+
+	future := time.Now().Add(time.Hour)
+	readme := filepath.Join(treeRoot, "README.md")
+	assert.NoError(t, os.WriteFile(readme, []byte("---\ntitle: [unclosed\n---\n[faq](faq.md)\n"), 0644))
+	assert.NoError(t, os.Chtimes(readme, future, future))
+	changed, documents, err := graph.Update()
+	assert.NoError(t, err, "Front matter that does not parse should only be warned about")
+	assert.True(t, changed)
+	assert.Equal(t, []string{"README.md"}, documents)
+	assert.Equal(t, []string{"faq.md"}, graph.Nodes()[0].NormalizedLocalRelativeLinks)
+}
+
+func TestIncrementalGraphParseFailure(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "[install](docs/install.md)\n",
+		"docs/install.md": "# Install\n",
+	})
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	assert.NoError(t, err)
+	graph, err := NewIncrementalGraph(treeRoot, nil, []string{".md"}, false, parser)
+	assert.NoError(t, err)
+
+	// A link outside of the tree root can't be normalized
+	future := time.Now().Add(time.Hour)
+	write := func(path string, content string) {
+		assert.NoError(t, os.WriteFile(filepath.Join(treeRoot, path), []byte(content), 0644))
+		assert.NoError(t, os.Chtimes(filepath.Join(treeRoot, path), future, future))
+	}
+	write("docs/install.md", "[outside](../../outside.md)\n")
+	write("docs/new.md", "[outside](../../outside.md)\n")
+	write("README.md", "[install](docs/install.md) [gone](docs/gone.md)\n")
+	changed, documents, err := graph.Update()
+	assert.NoError(t, err, "Documents failing to parse should not fail the update")
+	assert.True(t, changed)
+	assert.Equal(t, []string{"README.md", "docs/install.md", "docs/new.md"}, documents)
+	assert.Equal(t, []string{"docs/install.md", "docs/new.md"}, sortedKeys(graph.Failures()))
+	nodes := graph.Nodes()
+	assert.Equal(t, []string{"README.md", "docs/install.md"}, nodePaths(nodes), "The last parsed node should be kept")
+	assert.Equal(t, []string{"docs/install.md", "docs/gone.md"}, nodes[0].NormalizedLocalRelativeLinks,
+		"Other documents should be parsed")
+
+	changed, documents, err = graph.Update()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, documents)
+
+	future = future.Add(time.Hour)
+	write("docs/install.md", "# Install\n")
+	changed, documents, err = graph.Update()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"docs/install.md"}, documents)
+	assert.Equal(t, []string{"docs/new.md"}, sortedKeys(graph.Failures()))
+}
+
+func nodePaths(nodes []LinkGraphNode) []string {
+	var paths []string
+	for _, node := range nodes {
+		paths = append(paths, node.RelativePath)
+	}
+	return paths
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+func init() {
+	var backlinksCmd = &cobra.Command{
+		Use:   "backlinks <path>",
+		Short: "Lists the documents linking to a file or directory",
+		Long: `Lists every document and line linking to a file or directory, or to anything within the directory.
+
+Links to a directory are included when they resolve to the file through an implicit index,
+ie, a link to foo/ is a link to foo/README.md. The path is relative to the current directory.
+Each link is listed as <document>:<line>: <destination>, without the line if it could not be located.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBacklinks(cmd.Context(), args[0], cmd.OutOrStdout())
+		},
+	}
+
+	rootCmd.AddCommand(backlinksCmd)
+}
+
+func runBacklinks(ctx context.Context, targetArg string, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+	target, err := pathFromTreeRoot(absTreeRoot, targetArg)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	backlinks, err := checkdoc.FindBacklinks(absTreeRoot, nodes, options.ImplicitIndexes, options.Parser, target)
+	if err != nil {
+		return err
+	}
+
+	if len(backlinks) == 0 {
+		slog.Info("Nothing links to the target", "target", target)
+		return nil
+	}
+	for _, backlink := range backlinks {
+		location := backlink.DocumentPath
+		if backlink.Line > 0 {
+			location += fmt.Sprintf(":%d", backlink.Line)
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s\n", location, backlink.Destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
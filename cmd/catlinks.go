@@ -1,13 +1,17 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -15,7 +19,18 @@ import (
 	"github.com/open-ch/checkdoc/checkdoc"
 )
 
-var outputPath string
+const (
+	csvFormat   = "csv"
+	bazelFormat = "bazel"
+)
+
+var (
+	catLinksFormat     string
+	includeMarkdown    bool
+	includeDirectories bool
+	bazelWrite         bool
+	bazelCheck         bool
+)
 
 func init() {
 	// verifyCmd represents the verify command
@@ -23,91 +38,198 @@ func init() {
 		Use:   "catlinks",
 		Short: "Searches and dumps internal links found in the documentation files",
 		Long: `Searches and dumps internal links found int documentation files:
-This only includes links to local files, and does not include any HTTP, FTP or any other such link.`,
+This only includes links to local files, and does not include any HTTP, FTP or any other such link.
+
+By default, the sorted targets of the links are written, one per line. With --format json or csv,
+each link is written with the document and line it comes from, as written and normalized to the root.
+Links to markdown documents and to directories are left out, unless included explicitly.
+
+With --format bazel, the targets are grouped by Bazel package, ie, the closest directory with a BUILD file,
+and exports_files and filegroup rules declaring them are written for each package. The filegroup is named
+"doc_references", so that documentation targets can depend on it as data. With --write, the rules are kept
+up to date in the BUILD files themselves, between the following markers:
+  ` + checkdoc.BazelStartMarker + `
+  ` + checkdoc.BazelEndMarker + `
+With --check, nothing is written: the command fails if any BUILD file is out of date.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCatLinks()
+			if !slices.Contains([]string{textFormat, jsonFormat, csvFormat, bazelFormat}, catLinksFormat) {
+				return fmt.Errorf("unknown format %q, expected %s, %s, %s or %s",
+					catLinksFormat, textFormat, jsonFormat, csvFormat, bazelFormat)
+			}
+			if (bazelWrite || bazelCheck) && catLinksFormat != bazelFormat {
+				return fmt.Errorf("--write and --check require --format %s", bazelFormat)
+			}
+			if bazelWrite && bazelCheck {
+				return fmt.Errorf("--write and --check are mutually exclusive")
+			}
+			return runCatLinks(cmd.Context())
 		},
 	}
 
 	catLinksCmd.Flags().StringVarP(&outputPath, "output", "o", "",
 		"File to write the output to. Will output to STDOUT if not set.")
+	catLinksCmd.Flags().StringVarP(&catLinksFormat, "format", "f", textFormat,
+		fmt.Sprintf("Output format, one of %s (targets only), %s, %s or %s (BUILD rules).",
+			textFormat, jsonFormat, csvFormat, bazelFormat))
+	catLinksCmd.Flags().BoolVar(&includeMarkdown, "include-markdown", false,
+		"Include links to markdown documents.")
+	catLinksCmd.Flags().BoolVar(&includeDirectories, "include-directories", false,
+		"Include links to directories.")
+	catLinksCmd.Flags().BoolVar(&bazelWrite, "write", false,
+		"With --format bazel, update the rules in the BUILD files instead of writing them out.")
+	catLinksCmd.Flags().BoolVar(&bazelCheck, "check", false,
+		"With --format bazel, fail if the rules in any BUILD file are out of date.")
 
 	rootCmd.AddCommand(catLinksCmd)
 }
 
-func runCatLinks() error {
-	// TODO avoid globals treeRoot and resolveRepoRoot
-	absTreeRoot, err := filepath.Abs(treeRoot)
+func runCatLinks(ctx context.Context) error {
+	checker, err := newChecker()
 	if err != nil {
-		return fmt.Errorf("Could not convert %s to an absolute path: %w", treeRoot, err)
+		return err
 	}
 
-	if resolveRepoRoot {
-		repoRoot, err := getRepositoryRoot(absTreeRoot)
-		if err != nil {
-			return fmt.Errorf("Failed to find git repo root from path %s: %w", absTreeRoot, err)
+	return writeOutput(outputPath, func(output io.Writer) error {
+		return catLinks(ctx, checker, output)
+	})
+}
+
+func catLinks(ctx context.Context, checker *checkdoc.Checker, output io.Writer) error {
+	options := checker.Options()
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return err
+	}
+	records, err := checkdoc.ListLinks(options.TreeRoot, nodes, options.Parser)
+	if err != nil {
+		return err
+	}
+	records = filterLinks(records)
+
+	switch catLinksFormat {
+	case jsonFormat:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case csvFormat:
+		return writeLinksCSV(output, records)
+	case bazelFormat:
+		return bazelRules(options.TreeRoot, output, records)
+	default:
+		return writeTargets(output, records)
+	}
+}
+
+// writeTargets writes the distinct targets of the passed links, sorted, one per line.
+func writeTargets(output io.Writer, records []checkdoc.LinkRecord) error {
+	var targets []string
+	for _, record := range records {
+		targets = append(targets, record.Target)
+	}
+	slices.Sort(targets)
+
+	var err error
+	for _, target := range slices.Compact(targets) {
+		_, printerr := fmt.Fprintln(output, target)
+		err = errors.Join(err, printerr)
+	}
+	return err
+}
+
+// bazelRules writes the BUILD rules declaring the file targets of the passed links, or with --write and
+// --check, updates or checks them in the BUILD files.
+func bazelRules(treeRoot string, output io.Writer, records []checkdoc.LinkRecord) error {
+	var files []string
+	for _, record := range records {
+		if !strings.HasSuffix(record.Target, "/") {
+			files = append(files, record.Target)
+		}
+	}
+	packages, outside := checkdoc.GroupByBazelPackage(treeRoot, files)
+	for _, file := range outside {
+		slog.Warn("Linked file is not part of any Bazel package", "file", file)
+	}
+
+	if !bazelWrite && !bazelCheck {
+		var err error
+		for i, pkg := range packages {
+			if i > 0 {
+				_, printerr := fmt.Fprintln(output)
+				err = errors.Join(err, printerr)
+			}
+			_, printerr := fmt.Fprintf(output, "# %s\n%s", pkg.BuildFile, checkdoc.BazelRules(pkg))
+			err = errors.Join(err, printerr)
 		}
-		absTreeRoot = repoRoot
+		return err
 	}
 
-	var outputWriter io.Writer
-	if outputPath == "" {
-		outputWriter = io.Writer(os.Stdout)
-	} else {
-		outfile, err := os.Create(outputPath)
+	updates, err := checkdoc.PlanBuildFileUpdates(treeRoot, packages)
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		slog.Info("BUILD files are up to date")
+		return nil
+	}
+	if bazelCheck {
+		for _, update := range updates {
+			slog.Error("BUILD file is out of date", "file", update.Path)
+		}
+		return fmt.Errorf("%d BUILD files are out of date, run checkdoc catlinks --format %s --write",
+			len(updates), bazelFormat)
+	}
+	for _, update := range updates {
+		absPath := filepath.Join(treeRoot, update.Path)
+		info, err := os.Stat(absPath)
 		if err != nil {
 			return err
 		}
-		defer outfile.Close()
-		buff := bufio.NewWriter(outfile)
-		defer buff.Flush()
-		outputWriter = buff
+		if err := os.WriteFile(absPath, update.Updated, info.Mode().Perm()); err != nil {
+			return err
+		}
+		slog.Info("Updated BUILD file", "file", update.Path)
 	}
-	return catLinks(absTreeRoot, respectGitIgnore, outputWriter)
+	return nil
 }
 
-func catLinks(treeRoot string, respectGitIgnore bool, output io.Writer) error {
-	slog.Debug("building links using configured basenames and extensions",
-		"basenames", baseNames, "extensions", extensions)
-	nodes, err := checkdoc.BuildLinkGraphNodes(treeRoot, baseNames, extensions, respectGitIgnore)
-	if err != nil {
+func writeLinksCSV(output io.Writer, records []checkdoc.LinkRecord) error {
+	writer := csv.NewWriter(output)
+	if err := writer.Write([]string{"source", "line", "link", "target"}); err != nil {
 		return err
 	}
-	localPaths := checkdoc.BuildLocalPathSet(nodes)
-	localPathsWithSlash, err := checkdoc.EnsureDirectoriesEndWithSlash(treeRoot, localPaths)
-	filteredPaths := filterLinks(localPathsWithSlash)
-
-	for path := range filteredPaths {
-		_, printerr := fmt.Fprintln(output, path)
-		err = errors.Join(err, printerr)
+	for _, record := range records {
+		if err := writer.Write([]string{record.Source, strconv.Itoa(record.Line), record.Link, record.Target}); err != nil {
+			return err
+		}
 	}
-
-	return err
+	writer.Flush()
+	return writer.Error()
 }
 
-// We're interested in:
+// By default, we're interested in:
 //   - excluding links to markdown files (implicit or explicit)
 //   - excluding links to big files (?)
 //   - including links to any local source file
 //   - exclude non-file links (not relevant as long as we only deal with files)
 //
 // This means:
-//   - filter out anything that ends in .md, README or CHANGELOG
-//   - filter out anything that points to a directory (implicitly that's a README)
-//     -
-func filterLinks(paths map[string]bool) map[string]bool {
-	filtered := make(map[string]bool)
-	for path := range paths {
-		if !discardPath(path) {
-			filtered[path] = false
+//   - filter out anything that ends in .md, README or CHANGELOG, unless markdown is included
+//   - filter out anything that points to a directory (implicitly that's a README), unless directories are included
+func filterLinks(records []checkdoc.LinkRecord) []checkdoc.LinkRecord {
+	var filtered []checkdoc.LinkRecord
+	for _, record := range records {
+		if !discardPath(record.Target) {
+			filtered = append(filtered, record)
 		}
 	}
 	return filtered
 }
 
 func discardPath(path string) bool {
-	return strings.HasSuffix(path, ".md") ||
-		strings.HasSuffix(path, "/") ||
+	if strings.HasSuffix(path, "/") {
+		return !includeDirectories
+	}
+	return !includeMarkdown && (strings.HasSuffix(path, ".md") ||
 		strings.HasSuffix(path, "README") ||
-		strings.HasSuffix(path, "CHANGELOG")
+		strings.HasSuffix(path, "CHANGELOG"))
 }
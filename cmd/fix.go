@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+var (
+	fixDryRun         bool
+	fixInteractive    bool
+	fixUseSuggestions bool
+	fixExplicitLinks  bool
+)
+
+func init() {
+	var fixCmd = &cobra.Command{
+		Use:   "fix",
+		Short: "Rewrites dead links whose target was moved",
+		Long: `Looks for the new location of the target of each dead link, and rewrites the link in place.
+
+The new location is found from the git rename history, or else from a unique file with the same name in the tree.
+With --use-suggestions, the best "did you mean" suggestion is used as a last resort, if it stands out.
+With --explicit-links, links to a directory's README.md or missing the .md extension are rewritten to the document.
+With link style flags, links not following the style are rewritten to an equivalent link that does.
+Only the link destinations are rewritten: the rest of the documents is kept byte for byte.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFix(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false,
+		"Print a unified diff of the fixes instead of applying them.")
+	fixCmd.Flags().BoolVarP(&fixInteractive, "interactive", "i", false,
+		"Ask for a confirmation before each fix.")
+	fixCmd.Flags().BoolVar(&fixUseSuggestions, "use-suggestions", false,
+		"Fall back to the suggestions of verify to relocate dead links.")
+	fixCmd.Flags().BoolVar(&fixExplicitLinks, "explicit-links", false,
+		"Also rewrite links relying on implicit resolution to the document they resolve to.")
+	addLinkStyleFlags(fixCmd)
+
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(ctx context.Context, in io.Reader, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	reports := checkdoc.BuildReport(absTreeRoot, nodes, options.ImplicitIndexes, options.RootDocuments)
+
+	relocators, err := buildRelocators(absTreeRoot)
+	if err != nil {
+		return err
+	}
+	if fixUseSuggestions {
+		checkdoc.SuggestTargets(absTreeRoot, reports, options.RespectGitIgnore)
+		relocators = append(relocators, checkdoc.NewSuggestionRelocator(reports))
+	}
+	fixes, unfixable := checkdoc.PlanFixes(absTreeRoot, reports, relocators)
+	if fixExplicitLinks {
+		checkdoc.CheckExplicitLinks(absTreeRoot, reports, options.ImplicitIndexes, options.Extensions)
+		explicitFixes := checkdoc.PlanExplicitLinkFixes(absTreeRoot, reports)
+		fixes = checkdoc.MergeFixes(explicitFixes, fixes)
+		unfixable = withoutFixed(unfixable, explicitFixes)
+	}
+	if options.LinkStyle != nil && options.LinkStyle.Enabled() {
+		// Style fixes come last: they only apply to links that are not rewritten otherwise.
+		checkdoc.CheckLinkStyle(absTreeRoot, reports, *options.LinkStyle)
+		fixes = checkdoc.MergeFixes(fixes, checkdoc.PlanLinkStyleFixes(reports))
+	}
+
+	confirm := bufio.NewReader(in)
+	for len(fixes) > 0 {
+		documentPath := fixes[0].DocumentPath
+		var documentFixes []checkdoc.LinkFix
+		for len(fixes) > 0 && fixes[0].DocumentPath == documentPath {
+			if !fixInteractive || askConfirmation(confirm, out, fixes[0]) {
+				documentFixes = append(documentFixes, fixes[0])
+			}
+			fixes = fixes[1:]
+		}
+		if err := fixDocument(absTreeRoot, options.Parser, documentPath, documentFixes, fixDryRun, out); err != nil {
+			return err
+		}
+	}
+
+	logUnfixable(unfixable)
+	return nil
+}
+
+// withoutFixed removes the dead links that the passed fixes rewrite from the unfixable ones.
+func withoutFixed(unfixable map[string][]string, fixes []checkdoc.LinkFix) map[string][]string {
+	for _, fix := range fixes {
+		unfixable[fix.DocumentPath] = slices.DeleteFunc(unfixable[fix.DocumentPath], func(deadLink string) bool {
+			return deadLink == fix.DeadLink
+		})
+		if len(unfixable[fix.DocumentPath]) == 0 {
+			delete(unfixable, fix.DocumentPath)
+		}
+	}
+	return unfixable
+}
+
+func buildRelocators(treeRoot string) ([]checkdoc.Relocator, error) {
+	var relocators []checkdoc.Relocator
+	gitRelocator, err := checkdoc.NewGitRenameRelocator(treeRoot)
+	if err != nil {
+		slog.Warn("Not using the git history to relocate dead links", "err", err)
+	} else {
+		relocators = append(relocators, gitRelocator)
+	}
+
+	basenameRelocator, err := checkdoc.NewBasenameRelocator(treeRoot)
+	if err != nil {
+		return nil, err
+	}
+	return append(relocators, basenameRelocator), nil
+}
+
+func askConfirmation(in *bufio.Reader, out io.Writer, fix checkdoc.LinkFix) bool {
+	fmt.Fprintf(out, "%s: rewrite %s to %s? [y/N] ", fix.DocumentPath, fix.OldDestination, fix.NewDestination)
+	answer, err := in.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// fixDocument applies the passed fixes to a document, or prints the resulting diff on a dry run.
+func fixDocument(
+	treeRoot string,
+	parser markdown.Parser,
+	documentPath string,
+	fixes []checkdoc.LinkFix,
+	dryRun bool,
+	out io.Writer,
+) error {
+	if len(fixes) == 0 {
+		return nil
+	}
+	absPath := filepath.Join(treeRoot, documentPath)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+
+	fixed, applied := checkdoc.ApplyFixes(parser, source, fixes)
+	if len(applied) != len(fixes) {
+		slog.Warn("Could not locate some links in the document source, they were left untouched",
+			"document", documentPath, "expected", len(fixes), "applied", len(applied))
+	}
+
+	if dryRun {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(source)),
+			B:        difflib.SplitLines(string(fixed)),
+			FromFile: "a/" + documentPath,
+			ToFile:   "b/" + documentPath,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, diff)
+		return err
+	}
+
+	for _, fix := range applied {
+		slog.Info("Rewrote link", "document", documentPath, "from", fix.OldDestination, "to", fix.NewDestination)
+	}
+	return os.WriteFile(absPath, fixed, info.Mode().Perm())
+}
+
+func logUnfixable(unfixable map[string][]string) {
+	if len(unfixable) == 0 {
+		return
+	}
+	slog.Warn("Could not find a new location for some dead links:")
+	for path, deadLinks := range unfixable {
+		slog.Warn(fmt.Sprintf("\t%s", path))
+		for _, deadLink := range deadLinks {
+			slog.Warn(fmt.Sprintf("\t\t%s", deadLink))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+// Formats the link graph can be exported in
+const (
+	dotFormat     = "dot"
+	mermaidFormat = "mermaid"
+)
+
+var graphFormat string
+
+func init() {
+	var graphCmd = &cobra.Command{
+		Use:   "graph",
+		Short: "Exports the link graph between documents",
+		Long: `Exports the link graph between the documents, as Graphviz DOT, a Mermaid flowchart or JSON.
+
+Documents are grouped by directory. Orphans and documents with dead links are coloured.
+Links to a directory point to its implicit index. Links to anything else than a document are left out.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if graphFormat != dotFormat && graphFormat != mermaidFormat && graphFormat != jsonFormat {
+				return fmt.Errorf("unknown format %q, expected %s, %s or %s",
+					graphFormat, dotFormat, mermaidFormat, jsonFormat)
+			}
+			return runGraph(cmd.Context())
+		},
+	}
+
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", dotFormat,
+		fmt.Sprintf("Output format, one of %s, %s or %s.", dotFormat, mermaidFormat, jsonFormat))
+	graphCmd.Flags().StringVarP(&outputPath, "output", "o", "",
+		"File to write the output to. Will output to STDOUT if not set.")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(ctx context.Context) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	reports := checkdoc.BuildReport(absTreeRoot, nodes, options.ImplicitIndexes, options.RootDocuments)
+	graph := checkdoc.BuildGraph(absTreeRoot, reports, options.ImplicitIndexes)
+
+	return writeOutput(outputPath, func(output io.Writer) error {
+		switch graphFormat {
+		case mermaidFormat:
+			return checkdoc.WriteMermaid(output, graph)
+		case jsonFormat:
+			return checkdoc.WriteJSONGraph(output, graph)
+		default:
+			return checkdoc.WriteDOT(output, graph)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+var impactFormat string
+
+func init() {
+	var impactCmd = &cobra.Command{
+		Use:   "impact [file]...",
+		Short: "Lists the documents referring to some source files",
+		Long: `Lists every document linking to any of the given files, or to any of their parent directories.
+This tells which documents may need a review when these files change.
+
+The files given as arguments are relative to the current directory. If none is given, they are read from STDIN,
+one per line, relative to the root of the git repository, as git diff --name-only prints them.
+Files outside the tree root are then skipped. They need not exist anymore. Markdown documents are ignored: use backlinks for those.
+With --format json, each document comes with the given files it refers to.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if impactFormat != textFormat && impactFormat != jsonFormat {
+				return fmt.Errorf("unknown format %q, expected %s or %s", impactFormat, textFormat, jsonFormat)
+			}
+			checker, err := newChecker()
+			if err != nil {
+				return err
+			}
+			files, err := impactFiles(checker.Options().TreeRoot, args, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			return runImpact(cmd.Context(), checker, files, cmd.OutOrStdout())
+		},
+	}
+
+	impactCmd.Flags().StringVarP(&impactFormat, "format", "f", textFormat,
+		fmt.Sprintf("Output format, %s (documents only) or %s.", textFormat, jsonFormat))
+
+	rootCmd.AddCommand(impactCmd)
+}
+
+// readLines returns the non-blank lines of input, trimmed.
+func readLines(input io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// impactFiles returns the files to look for, relative to the tree root: the passed arguments, relative to the current
+// directory, or else the lines of input, relative to the repository root. Lines outside the tree root are skipped.
+func impactFiles(absTreeRoot string, args []string, input io.Reader) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		file, err := pathFromTreeRoot(absTreeRoot, arg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(args) > 0 {
+		return files, nil
+	}
+
+	lines, err := readLines(input)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := getRepositoryRoot(absTreeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find git repo root from path %s: %w", absTreeRoot, err)
+	}
+	for _, line := range lines {
+		file, err := pathFromTreeRoot(absTreeRoot, filepath.Join(repoRoot, line))
+		if err != nil {
+			slog.Debug("Skipping file outside of the tree root", "file", line)
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func runImpact(ctx context.Context, checker *checkdoc.Checker, files []string, out io.Writer) error {
+	absTreeRoot := checker.Options().TreeRoot
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	impacted := checkdoc.FindImpactedDocuments(nodes, files)
+
+	if impactFormat == jsonFormat {
+		if impacted == nil {
+			impacted = []checkdoc.ImpactedDocument{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(impacted)
+	}
+	if len(impacted) == 0 {
+		slog.Info("No document refers to the files")
+		return nil
+	}
+	for _, document := range impacted {
+		if _, err := fmt.Fprintln(out, document.DocumentPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
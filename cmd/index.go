@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+var (
+	indexDocument string
+	indexCheck    bool
+)
+
+func init() {
+	var indexCmd = &cobra.Command{
+		Use:   "index",
+		Short: "Generates a sitemap of the documentation",
+		Long: `Generates a markdown sitemap of all documents, nested by directory and titled from each document's first heading.
+
+The sitemap is written to STDOUT, or with --document, between the following markers of the given document:
+  ` + checkdoc.IndexStartMarker + `
+  ` + checkdoc.IndexEndMarker + `
+With --check, nothing is written: the command fails if the sitemap in the document is out of date.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if indexCheck && indexDocument == "" {
+				return fmt.Errorf("--check requires --document")
+			}
+			return runIndex(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	indexCmd.Flags().StringVarP(&indexDocument, "document", "d", "",
+		"Document to keep the sitemap up to date in, relative to the current directory.")
+	indexCmd.Flags().BoolVar(&indexCheck, "check", false,
+		"Fail if the sitemap of --document is out of date, instead of updating it.")
+
+	rootCmd.AddCommand(indexCmd)
+}
+
+func runIndex(ctx context.Context, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+
+	if indexDocument == "" {
+		_, err := io.WriteString(out, checkdoc.BuildIndex(nodes, options.ImplicitIndexes, ""))
+		return err
+	}
+
+	documentPath, err := pathFromTreeRoot(absTreeRoot, indexDocument)
+	if err != nil {
+		return err
+	}
+	absPath := filepath.Join(absTreeRoot, documentPath)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+	updated, err := checkdoc.UpdateIndexRegion(source, checkdoc.BuildIndex(nodes, options.ImplicitIndexes, documentPath))
+	if err != nil {
+		return fmt.Errorf("Could not update the index of %s: %w", documentPath, err)
+	}
+
+	if bytes.Equal(source, updated) {
+		slog.Info("Index is up to date", "document", documentPath)
+		return nil
+	}
+	if indexCheck {
+		return fmt.Errorf("the index of %s is out of date, run checkdoc index --document %s", documentPath, documentPath)
+	}
+	slog.Info("Updated index", "document", documentPath)
+	return os.WriteFile(absPath, updated, info.Mode().Perm())
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/lsp"
+)
+
+func init() {
+	var lspCmd = &cobra.Command{
+		Use:   "lsp",
+		Short: "Runs a language server for the documentation",
+		Long: `Runs a Language Server Protocol server over STDIN and STDOUT, for editors to use. It offers:
+ - diagnostics for dead links and images, links to missing anchors and orphan documents, updated as documents change.
+ - go to definition on links, leading to the linked file or to the heading of the linked anchor.
+ - completion of paths and anchors in link destinations.
+ - find references, ie, the links to the document or to the target of the link under the cursor.
+ - rename of the document or of the target of the link under the cursor, updating the links to it.
+   The new name is relative to the root.
+
+The link graph is kept in memory: open documents are parsed from their unsaved content,
+other documents are parsed again when a document is saved.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLsp()
+		},
+	}
+
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLsp() error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+
+	server := lsp.NewServer(lsp.Config{
+		TreeRoot:         options.TreeRoot,
+		BaseNames:        options.BaseNames,
+		Extensions:       options.Extensions,
+		ImplicitIndexes:  options.ImplicitIndexes,
+		RootDocuments:    options.RootDocuments,
+		RespectGitIgnore: options.RespectGitIgnore,
+		Parser:           options.Parser,
+	})
+	return server.Run(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+var mvDryRun bool
+
+func init() {
+	var mvCmd = &cobra.Command{
+		Use:   "mv <old> <new>",
+		Short: "Moves a document or directory and updates the links to it",
+		Long: `Moves a file or directory with git, and updates every link pointing to it across the documentation.
+
+Relative links within the moved documents are rewritten as well, so that they still resolve from their new location.
+Both paths are relative to the current directory, as with git mv.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMv(cmd.Context(), args[0], args[1], cmd.OutOrStdout())
+		},
+	}
+
+	mvCmd.Flags().BoolVar(&mvDryRun, "dry-run", false,
+		"Print a unified diff of the link updates instead of moving anything.")
+
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMv(ctx context.Context, oldArg string, newArg string, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+	oldPath, err := pathFromTreeRoot(absTreeRoot, oldArg)
+	if err != nil {
+		return err
+	}
+	newPath, err := pathFromTreeRoot(absTreeRoot, newArg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(absTreeRoot, oldPath)); err != nil {
+		return fmt.Errorf("Cannot move %s: %w", oldArg, err)
+	}
+	if info, err := os.Stat(filepath.Join(absTreeRoot, newPath)); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("Cannot move %s: %s already exists", oldArg, newArg)
+		}
+		// Like git mv, moving to an existing directory moves into it.
+		newPath = filepath.Join(newPath, filepath.Base(oldPath))
+	}
+
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	fixes := checkdoc.PlanMove(absTreeRoot, nodes, options.ImplicitIndexes, oldPath, newPath)
+
+	if mvDryRun {
+		fmt.Fprintf(out, "Would move %s to %s\n", oldPath, newPath)
+		return fixDocuments(absTreeRoot, options.Parser, fixes, out, func(path string) string { return path })
+	}
+
+	if err := gitMove(absTreeRoot, oldPath, newPath); err != nil {
+		return err
+	}
+	slog.Info("Moved", "from", oldPath, "to", newPath)
+	return fixDocuments(absTreeRoot, options.Parser, fixes, out, func(path string) string {
+		return checkdoc.MovedPath(path, oldPath, newPath)
+	})
+}
+
+// fixDocuments applies the passed fixes, sorted by document, to each document.
+// The location of each document is obtained through locate.
+func fixDocuments(
+	treeRoot string,
+	parser markdown.Parser,
+	fixes []checkdoc.LinkFix,
+	out io.Writer,
+	locate func(string) string,
+) error {
+	for len(fixes) > 0 {
+		documentPath := fixes[0].DocumentPath
+		end := 1
+		for end < len(fixes) && fixes[end].DocumentPath == documentPath {
+			end++
+		}
+		if err := fixDocument(treeRoot, parser, locate(documentPath), fixes[:end], mvDryRun, out); err != nil {
+			return err
+		}
+		fixes = fixes[end:]
+	}
+	return nil
+}
+
+// pathFromTreeRoot converts a path relative to the current directory to a path relative to the tree root.
+func pathFromTreeRoot(treeRoot string, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := filepath.Rel(treeRoot, absPath)
+	if err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..") {
+		return "", fmt.Errorf("%s is not within the tree root %s", path, treeRoot)
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+func gitMove(treeRoot string, oldPath string, newPath string) error {
+	if err := os.MkdirAll(filepath.Join(treeRoot, filepath.Dir(newPath)), 0755); err != nil {
+		return err
+	}
+	gitCmd := exec.Command("git", "mv", oldPath, newPath)
+	gitCmd.Dir = treeRoot
+	if output, err := gitCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git mv %s %s failed: %s: %w", oldPath, newPath, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
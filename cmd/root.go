@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
 )
 
 var (
@@ -14,8 +22,20 @@ var (
 
 	respectGitIgnore bool
 
+	// Name of the markdown parser backend to use
+	parserBackend string
+
 	verbose bool
 
+	// How local links are expected to be written
+	linkStyle checkdoc.LinkStyle
+
+	// Where readers start browsing the documentation, relative to the tree root
+	rootDocuments []string
+
+	// File commands write their output to, STDOUT if empty
+	outputPath string
+
 	rootCmd = &cobra.Command{
 		// Don't show usage when reporting errors.
 		// Only show with -h, --help or when subcommands are missing.
@@ -57,9 +77,89 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&respectGitIgnore, "respect-git-ignore", true,
 		`If true, will check all potential documents against the repository's gitignore files.'`)
 
+	rootCmd.PersistentFlags().StringVar(&parserBackend, "parser", markdown.BlackfridayBackend,
+		fmt.Sprintf("Markdown parser backend to use, one of %v. %s follows CommonMark and GitHub's rendering.",
+			markdown.Backends, markdown.GFMBackend))
+
+	rootCmd.PersistentFlags().StringSliceVar(&rootDocuments, "root-document", []string{"README.md"},
+		"Documents readers start browsing from, relative to the root. They need no link to them, "+
+			"and click depths are computed from them.")
+
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Detailed output if true")
 }
 
+// resolveTreeRoot returns the absolute path to the configured tree root,
+// or to the root of the repository containing it if required.
+func resolveTreeRoot() (string, error) {
+	absTreeRoot, err := filepath.Abs(treeRoot)
+	if err != nil {
+		return "", fmt.Errorf("Could not convert %s to an absolute path: %w", treeRoot, err)
+	}
+
+	if resolveRepoRoot {
+		repoRoot, err := getRepositoryRoot(absTreeRoot)
+		if err != nil {
+			return "", fmt.Errorf("Failed to find git repo root from path %s: %w", absTreeRoot, err)
+		}
+		absTreeRoot = repoRoot
+	}
+	return absTreeRoot, nil
+}
+
+// newChecker returns a checker for the resolved tree root, configured with the flags.
+func newChecker() (*checkdoc.Checker, error) {
+	absTreeRoot, err := resolveTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	parser, err := markdown.NewParser(parserBackend)
+	if err != nil {
+		return nil, err
+	}
+	return checkdoc.NewChecker(checkdoc.Options{
+		TreeRoot:             absTreeRoot,
+		BaseNames:            baseNames,
+		Extensions:           extensions,
+		ImplicitIndexes:      implicitIndexes,
+		RespectGitIgnore:     respectGitIgnore,
+		Parser:               parser,
+		Suggestions:          true,
+		Images:               imageChecks,
+		StrictDirectoryLinks: strictDirectories,
+		ExplicitLinks:        explicitLinks,
+		LinkStyle:            &linkStyle,
+		RootDocuments:        rootDocuments,
+		MaxDepth:             maxDepth,
+		IndexRequirements:    indexRequirements,
+	})
+}
+
+// addLinkStyleFlags adds the flags configuring the link style to the passed command.
+func addLinkStyleFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&linkStyle.ForbidRootAbsolute, "forbid-root-absolute-links", false,
+		"If true, links may not start with a '/' referring to the root.")
+	cmd.Flags().BoolVar(&linkStyle.ForbidParentLevels, "forbid-parent-links", false,
+		"If true, relative links may not climb up with '..' at all.")
+	cmd.Flags().IntVar(&linkStyle.MaxParentLevels, "max-parent-levels", 0,
+		"Maximum number of '..' levels relative links may climb up. No limit if 0.")
+	cmd.Flags().BoolVar(&linkStyle.RequireShortest, "require-shortest-links", false,
+		"If true, links must be the shortest of their relative and root-absolute forms.")
+}
+
+// writeOutput calls write with a writer to the passed file, or to STDOUT if path is empty.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	outfile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	buff := bufio.NewWriter(outfile)
+	err = write(buff)
+	return errors.Join(err, buff.Flush(), outfile.Close())
+}
+
 // Execute runs the whole enchilada, baby!
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/preview"
+)
+
+var serveAddress string
+
+func init() {
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serves a preview of the documentation, with its findings",
+		Long: `Renders the documentation as HTML on a local web server, resolving links the way git hosts do:
+relative to the document, or to the root when they start with a /. Directories show their entries and their README.md.
+
+Each page shows the findings of verify about it: broken links and images are highlighted, with the reason
+they are broken as tooltip, and orphan documents get a banner. The tree is polled for changes, and pages
+reload when it changes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return runServe(ctx)
+		},
+	}
+
+	serveCmd.Flags().StringVar(&serveAddress, "address", "localhost:8080", "Address to serve the documentation on.")
+	serveCmd.Flags().DurationVar(&watchInterval, "watch-interval", time.Second, "How often to check the tree for changes.")
+	addLinkStyleFlags(serveCmd)
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(ctx context.Context) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	server, err := preview.NewServer(ctx, checker, watchInterval)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{Addr: serveAddress, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go server.Watch(watchCtx)
+	go func() {
+		<-ctx.Done()
+		// Stopping to watch ends the event streams of the open pages, which would keep the server busy
+		stopWatching()
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			slog.Warn("Could not shut the server down", "err", err)
+		}
+	}()
+
+	slog.Info("Serving the documentation, interrupt to stop",
+		"rootpath", checker.Options().TreeRoot, "url", "http://"+serveAddress)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
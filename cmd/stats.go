@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+var statsFormat string
+
+func init() {
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Reports statistics about the documentation",
+		Long: `Reports statistics about the documentation and its link graph:
+ - documents per directory.
+ - how many documents link to each document, and how many it links to.
+ - the most linked documents, and documents not linking to any other.
+ - the average and maximum click depth from the root documents, and the documents they can't reach.
+ - links by kind: to documents, directories, other files, images, anchors, external or dead.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if statsFormat != textFormat && statsFormat != jsonFormat {
+				return fmt.Errorf("unknown format %q, expected %s or %s", statsFormat, textFormat, jsonFormat)
+			}
+			return runStats(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	statsCmd.Flags().StringVarP(&statsFormat, "format", "f", textFormat,
+		fmt.Sprintf("Output format, %s or %s.", textFormat, jsonFormat))
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(ctx context.Context, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	absTreeRoot := options.TreeRoot
+
+	nodes, err := checker.LinkGraph(ctx)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", absTreeRoot, err)
+	}
+	reports := checkdoc.BuildReport(absTreeRoot, nodes, options.ImplicitIndexes, options.RootDocuments)
+	stats := checkdoc.BuildStats(absTreeRoot, reports, options.ImplicitIndexes, options.RootDocuments)
+
+	if statsFormat == jsonFormat {
+		return checkdoc.WriteJSONStats(out, stats)
+	}
+	return checkdoc.WriteStats(out, stats)
+}
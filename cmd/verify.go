@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"os/exec"
-	"path/filepath"
+	"os/signal"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,66 +20,278 @@ import (
 // A file or dir name telling us we are at the root of a git repo
 const gitRootIndicator = ".git"
 
+// Formats verify can write its findings in
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+	htmlFormat = "html"
+)
+
+var (
+	imageChecks       checkdoc.ImageChecks
+	indexRequirements checkdoc.IndexRequirements
+	maxDepth          int
+	strictDirectories bool
+	explicitLinks     bool
+	verifyFormat      string
+	reportFiles       []string
+	watch             bool
+	watchInterval     time.Duration
+)
+
 func init() {
 	var verifyCmd = &cobra.Command{
 		Use:   "verify",
 		Short: "Runs sanity checks on the documentation",
 		Long: `Run some checks against the markdown documentation found in a directory hierarchy.
 
-Currently, verify will check for two things:
+Currently, verify will check for seven things:
  - orphan README.md files: these are files that are not linked to
    from the repo's root directory, either directly or indirectly.
- - broken links.`,
+ - broken links, including links to directories without README.md with --strict-directory-links.
+   With --explicit-links, links to a directory's README.md or missing the .md extension are reported too.
+ - optionally, links not following the link style: root-absolute, climbing up too many levels or not the shortest.
+ - broken images: missing, and optionally not really images or too big.
+ - reference-style links without definition, and unused or duplicate definitions.
+ - optionally, documents further away than --max-depth links from the root documents.
+ - optionally, directories required to contain an implicit index (README.md) that don't.
+
+With --report, the findings are also written to files, eg --report html=report.html for a self-contained page
+listing them by directory and by kind, with the source around the links at fault and the link graph.
+
+With --watch, verify keeps running after the first run, and polls the tree for changes: only the documents
+that changed are parsed again, and the findings that appeared or were fixed since are written out,
+prefixed with + and - respectively.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runVerify(respectGitIgnore)
+			if verifyFormat != textFormat && verifyFormat != jsonFormat {
+				return fmt.Errorf("unknown format %q, expected %s or %s", verifyFormat, textFormat, jsonFormat)
+			}
+			reports, err := parseReportFiles(reportFiles)
+			if err != nil {
+				return err
+			}
+			if watch {
+				if verifyFormat != textFormat {
+					return fmt.Errorf("--watch only supports --format %s", textFormat)
+				}
+				if len(reports) != 0 {
+					return fmt.Errorf("--watch does not support --report")
+				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return runWatch(ctx, cmd.OutOrStdout())
+			}
+			return runVerify(cmd.Context(), reports, cmd.OutOrStdout())
 		},
 	}
 
+	verifyCmd.Flags().StringVarP(&verifyFormat, "format", "f", textFormat,
+		fmt.Sprintf("Output format of the findings, %s (logged) or %s (written to STDOUT).", textFormat, jsonFormat))
+	verifyCmd.Flags().StringArrayVar(&reportFiles, "report", nil,
+		fmt.Sprintf("Format and file to also write the findings to, eg %s=report.html. Formats are %s and %s. Repeatable.",
+			htmlFormat, htmlFormat, jsonFormat))
+
+	verifyCmd.Flags().BoolVar(&imageChecks.VerifyContent, "check-image-content", false,
+		"If true, check that local images really are images, based on their content.")
+	verifyCmd.Flags().Int64Var(&imageChecks.MaxSize, "max-image-size", 0,
+		"Maximum size in bytes of local images. No limit if 0.")
+	verifyCmd.Flags().BoolVar(&strictDirectories, "strict-directory-links", false,
+		"If true, links to a directory must lead to a README.md within it, not to the bare directory.")
+	verifyCmd.Flags().BoolVar(&explicitLinks, "explicit-links", false,
+		"If true, links must point to documents explicitly, not to their directory or without their extension.")
+	addLinkStyleFlags(verifyCmd)
+	verifyCmd.Flags().IntVar(&maxDepth, "max-depth", 0,
+		"Maximum number of links to follow from the root documents to reach any document. No limit if 0.")
+
+	verifyCmd.Flags().StringSliceVar(&indexRequirements.Patterns, "require-index-in", nil,
+		"Glob patterns of directories, relative to the root, that must contain a README.md, eg services/*.")
+	verifyCmd.Flags().StringSliceVar(&indexRequirements.MarkerFiles, "require-index-with", nil,
+		"Names of files, eg BUILD, whose presence in a directory requires it to contain a README.md.")
+
+	verifyCmd.Flags().BoolVarP(&watch, "watch", "w", false,
+		"If true, keep watching the tree for changes and write out the findings that changed.")
+	verifyCmd.Flags().DurationVar(&watchInterval, "watch-interval", time.Second,
+		"How often to check the tree for changes with --watch.")
+
 	rootCmd.AddCommand(verifyCmd)
 }
 
-func runVerify(respectGitIgnore bool) error {
-	// TODO avoid globals treeRoot and resolveRepoRoot
-	absTreeRoot, err := filepath.Abs(treeRoot)
-	if err != nil {
-		return fmt.Errorf("Could not convert %s to an absolute path: %w", treeRoot, err)
-	}
-
-	if resolveRepoRoot {
-		repoRoot, err := getRepositoryRoot(absTreeRoot)
-		if err != nil {
-			return fmt.Errorf("Failed to find git repo root from path %s: %w", absTreeRoot, err)
+// parseReportFiles parses the values of --report, returning the file to write each format to.
+func parseReportFiles(values []string) (map[string]string, error) {
+	reports := make(map[string]string)
+	for _, value := range values {
+		format, path, found := strings.Cut(value, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("invalid report %q, expected <format>=<file>", value)
+		}
+		if format != htmlFormat && format != jsonFormat {
+			return nil, fmt.Errorf("unknown report format %q, expected %s or %s", format, htmlFormat, jsonFormat)
 		}
-		absTreeRoot = repoRoot
+		if _, duplicate := reports[format]; duplicate {
+			return nil, fmt.Errorf("report format %s given more than once", format)
+		}
+		reports[format] = path
 	}
-
-	slog.Info("Running verify on tree root", "rootpath", absTreeRoot)
-	return verifyTree(absTreeRoot, respectGitIgnore)
+	return reports, nil
 }
 
-func verifyTree(treeRoot string, respectGitIgnore bool) error {
-	slog.Debug("building links using configured basenames and extensions",
-		"basenames", baseNames, "extensions", extensions)
-	nodes, err := checkdoc.BuildLinkGraphNodes(treeRoot, baseNames, extensions, respectGitIgnore)
+func runVerify(ctx context.Context, reportFiles map[string]string, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+	treeRoot := options.TreeRoot
 
+	slog.Info("Running verify on tree root", "rootpath", treeRoot)
+	nodes, err := checker.LinkGraph(ctx)
 	if err != nil {
 		return fmt.Errorf("Could not build the link graph for tree root %s: %w", treeRoot, err)
 	}
 
 	logNodes(nodes)
 
-	reports := checkdoc.BuildReport(treeRoot, nodes, implicitIndexes)
-	if !checkdoc.ValidateReports(reports) {
+	result, err := checker.Check(ctx, nodes)
+	if err != nil {
+		return fmt.Errorf("Could not check tree root %s: %w", treeRoot, err)
+	}
+
+	valid := logResult(options, result)
+	if verifyFormat == jsonFormat {
+		if err := checkdoc.WriteJSONReport(out, result.Reports, result.DirectoriesWithoutIndex, valid); err != nil {
+			return err
+		}
+	}
+	if err := writeReportFiles(options, reportFiles, result, valid); err != nil {
+		return err
+	}
+	if !valid {
 		return fmt.Errorf("verify failed on tree root %s", treeRoot)
 	}
 	slog.Info("Validated doc tree root successfully")
 	return nil
 }
 
+// logResult logs the findings of checks run with the passed options, returning whether there were none.
+func logResult(options checkdoc.Options, result checkdoc.Result) bool {
+	valid := checkdoc.ValidateReports(result.Reports)
+	if options.IndexRequirements.Enabled() {
+		valid = checkdoc.ValidateDirectoryIndexes(result.DirectoriesWithoutIndex) && valid
+	}
+	return valid
+}
+
+// writeReportFiles writes the findings to the file of each report format.
+func writeReportFiles(options checkdoc.Options, reportFiles map[string]string, result checkdoc.Result, valid bool) error {
+	for format, path := range reportFiles {
+		err := writeOutput(path, func(output io.Writer) error {
+			if format == htmlFormat {
+				return checkdoc.WriteHTMLReport(output, options.TreeRoot, result.Reports, result.DirectoriesWithoutIndex,
+					options.ImplicitIndexes, options.Parser, valid)
+			}
+			return checkdoc.WriteJSONReport(output, result.Reports, result.DirectoriesWithoutIndex, valid)
+		})
+		if err != nil {
+			return fmt.Errorf("Could not write the %s report to %s: %w", format, path, err)
+		}
+		slog.Info("Wrote report", "format", format, "path", path)
+	}
+	return nil
+}
+
+// runWatch verifies the tree once, then writes out the findings that change whenever the tree does,
+// until ctx is done.
+func runWatch(ctx context.Context, out io.Writer) error {
+	checker, err := newChecker()
+	if err != nil {
+		return err
+	}
+	options := checker.Options()
+
+	slog.Info("Running verify on tree root", "rootpath", options.TreeRoot)
+	graph, err := checkdoc.NewIncrementalGraph(options.TreeRoot, options.BaseNames, options.Extensions,
+		options.RespectGitIgnore, options.Parser)
+	if err != nil {
+		return fmt.Errorf("Could not build the link graph for tree root %s: %w", options.TreeRoot, err)
+	}
+	warnParseFailures(graph, nil)
+	result, err := checker.Check(ctx, graph.Nodes())
+	if err != nil {
+		return fmt.Errorf("Could not check tree root %s: %w", options.TreeRoot, err)
+	}
+	logResult(options, result)
+	findings := result.Findings
+
+	slog.Info("Watching for changes, interrupt to stop", "interval", watchInterval)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, documents, err := graph.Update()
+		if err != nil {
+			// Files may be in the middle of being moved: try again on the next tick
+			slog.Warn("Could not update the link graph", "err", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		slog.Debug("Parsed changed documents", "documents", documents)
+		warnParseFailures(graph, documents)
+		result, err := checker.Check(ctx, graph.Nodes())
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			slog.Warn("Could not check the tree", "err", err)
+			continue
+		}
+		current := result.Findings
+		added, resolved := checkdoc.DiffFindings(findings, current)
+		findings = current
+		if err := writeFindingChanges(out, added, resolved); err != nil {
+			return err
+		}
+		if len(added) != 0 || len(resolved) != 0 {
+			slog.Info("Findings changed", "added", len(added), "fixed", len(resolved), "total", len(findings))
+		}
+	}
+}
+
+// warnParseFailures warns about the passed documents of the graph that failed to parse, or about all of them if nil.
+// Their last parsed version, if any, is checked instead.
+func warnParseFailures(graph *checkdoc.IncrementalGraph, documents []string) {
+	for document, err := range graph.Failures() {
+		if documents == nil || slices.Contains(documents, document) {
+			slog.Warn("Could not parse document, checking its last version", "document", document, "err", err)
+		}
+	}
+}
+
+// writeFindingChanges writes the fixed findings prefixed with -, then the new ones prefixed with +.
+func writeFindingChanges(out io.Writer, added []checkdoc.Finding, resolved []checkdoc.Finding) error {
+	for _, finding := range resolved {
+		if _, err := fmt.Fprintf(out, "- %s\n", finding); err != nil {
+			return err
+		}
+	}
+	for _, finding := range added {
+		if _, err := fmt.Fprintf(out, "+ %s\n", finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func logNodes(nodes []checkdoc.LinkGraphNode) {
 	slog.Debug("Found nodes", "nodescount", len(nodes))
 	for _, node := range nodes {
-		slog.Debug(fmt.Sprintf("\t%s:", node.RelativePath))
+		slog.Debug(fmt.Sprintf("\t%s:", node.RelativePath), "metadata", node.Metadata)
 	}
 }
 
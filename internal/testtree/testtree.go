@@ -0,0 +1,22 @@
+// Package testtree writes documentation trees for the tests of the other packages.
+package testtree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Write creates the passed files, keyed by their path relative to the returned temporary directory.
+func Write(t testing.TB, files map[string]string) string {
+	t.Helper()
+	treeRoot := t.TempDir()
+	for path, content := range files {
+		absPath := filepath.Join(treeRoot, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(absPath), 0755))
+		assert.NoError(t, os.WriteFile(absPath, []byte(content), 0644))
+	}
+	return treeRoot
+}
@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+// completion completes the destination of the inline link at the cursor: paths of files and directories,
+// and after a '#' the anchors of the headings of the document the link points to.
+func (s *Server) completion(p textDocumentPositionParams) ([]CompletionItem, error) {
+	source, node, err := s.documentAt(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	offset := positionToOffset(source, p.Position)
+	typed, found := typedDestination(source, offset)
+	if !found {
+		return []CompletionItem{}, nil
+	}
+
+	if linkPath, anchor, hasAnchor := strings.Cut(typed, "#"); hasAnchor {
+		return s.completeAnchors(source, node, linkPath, offset-len(anchor), offset), nil
+	}
+	directory, partial := "", typed
+	if slash := strings.LastIndex(typed, "/"); slash >= 0 {
+		directory, partial = typed[:slash+1], typed[slash+1:]
+	}
+	return s.completePaths(source, node, directory, partial, offset-len(partial), offset), nil
+}
+
+// typedDestination returns what was typed so far of the destination of an inline link ending at offset, if any.
+func typedDestination(source []byte, offset int) (string, bool) {
+	lineStart := bytes.LastIndexByte(source[:offset], '\n') + 1
+	linePrefix := source[lineStart:offset]
+	start := bytes.LastIndex(linePrefix, []byte("]("))
+	if start < 0 {
+		return "", false
+	}
+	typed := strings.TrimPrefix(string(linePrefix[start+2:]), "<")
+	if strings.ContainsAny(typed, " \t)>") || strings.Contains(typed, "://") || strings.HasPrefix(typed, "mailto:") {
+		return "", false
+	}
+	return typed, true
+}
+
+// resolve returns the path, relative to the root, that a destination typed in the document at documentPath leads to.
+func (s *Server) resolve(documentPath string, destination string) (string, bool) {
+	absPath := filepath.Join(s.config.TreeRoot, filepath.Dir(documentPath), destination)
+	if strings.HasPrefix(destination, "/") {
+		absPath = filepath.Join(s.config.TreeRoot, destination)
+	}
+	relativePath, err := s.relativePath(absPath)
+	return relativePath, err == nil
+}
+
+func (s *Server) completeAnchors(
+	source []byte,
+	node checkdoc.LinkGraphNode,
+	linkPath string,
+	start int,
+	end int,
+) []CompletionItem {
+	items := []CompletionItem{}
+	target := node
+	if linkPath != "" {
+		relativePath, found := s.resolve(node.RelativePath, linkPath)
+		if !found {
+			return items
+		}
+		relativePath = checkdoc.ResolveImplicitIndex(s.config.TreeRoot, s.config.ImplicitIndexes, relativePath)
+		if target, found = s.graph.Node(filepath.Join(s.config.TreeRoot, relativePath)); !found {
+			return items
+		}
+	}
+	for _, heading := range target.Document.Headings {
+		items = append(items, CompletionItem{
+			Label:    heading.Anchor,
+			Kind:     completionKindReference,
+			Detail:   strings.Repeat("#", heading.Level) + " " + heading.Text,
+			TextEdit: TextEdit{Range: rangeOf(source, start, end), NewText: heading.Anchor},
+		})
+	}
+	return items
+}
+
+func (s *Server) completePaths(
+	source []byte,
+	node checkdoc.LinkGraphNode,
+	directory string,
+	partial string,
+	start int,
+	end int,
+) []CompletionItem {
+	items := []CompletionItem{}
+	relativePath, found := s.resolve(node.RelativePath, directory)
+	if !found {
+		return items
+	}
+	entries, err := os.ReadDir(filepath.Join(s.config.TreeRoot, relativePath))
+	if err != nil {
+		return items
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == ".git" || (strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		kind := completionKindFile
+		if entry.IsDir() {
+			kind = completionKindFolder
+			name += "/"
+		}
+		items = append(items, CompletionItem{
+			Label:    name,
+			Kind:     kind,
+			TextEdit: TextEdit{Range: rangeOf(source, start, end), NewText: name},
+		})
+	}
+	return items
+}
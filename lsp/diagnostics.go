@@ -0,0 +1,172 @@
+package lsp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+// Source of the diagnostics, as shown by editors
+const diagnosticSource = "checkdoc"
+
+// Codes of the diagnostics of links to missing anchors, and of documents failing to parse.
+// Other codes are the kinds of findings of checkdoc.
+const (
+	deadAnchorCode = "dead-anchor"
+	parseErrorCode = "parse-error"
+)
+
+// publishDiagnostics checks the whole link graph, and publishes the diagnostics of each open document.
+func (s *Server) publishDiagnostics() error {
+	nodes := s.graph.Nodes()
+	s.reports = checkdoc.BuildReport(s.config.TreeRoot, nodes, s.config.ImplicitIndexes, s.config.RootDocuments)
+	checkdoc.SuggestTargets(s.config.TreeRoot, s.reports, s.config.RespectGitIgnore)
+	s.documents = make(map[string]checkdoc.LinkGraphNode)
+	for _, node := range nodes {
+		s.documents[node.RelativePath] = node
+	}
+
+	for _, absPath := range sortedPaths(s.versions) {
+		if err := s.publish(absPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishDocumentDiagnostics checks the document at uri alone, as it is being edited, and publishes its diagnostics.
+// Whether it is an orphan depends on other documents only: it is kept from the last check of the whole graph.
+// Looking for suggestions walks the whole tree, which is too slow to do on every edit: the suggestions
+// are kept from the last check as well, for the dead links that are still there.
+// The diagnostics of other open documents are updated when documents are opened, saved or closed.
+func (s *Server) publishDocumentDiagnostics(uri string) error {
+	absPath, err := s.uriToPath(uri)
+	if err != nil {
+		return err
+	}
+	node, isDocument := s.graph.Node(absPath)
+	if !isDocument {
+		return nil
+	}
+	report := checkdoc.BuildReport(s.config.TreeRoot, []checkdoc.LinkGraphNode{node}, s.config.ImplicitIndexes,
+		s.config.RootDocuments)[node.RelativePath]
+	if last, checked := s.reports[node.RelativePath]; checked {
+		report.IsOrphan = last.IsOrphan
+		for _, deadLink := range slices.Concat(report.DeadLinks, report.DeadImageLinks) {
+			if suggestions, found := last.Suggestions[deadLink]; found {
+				if report.Suggestions == nil {
+					report.Suggestions = make(map[string][]checkdoc.Suggestion)
+				}
+				report.Suggestions[deadLink] = suggestions
+			}
+		}
+	}
+	s.reports[node.RelativePath] = report
+	s.documents[node.RelativePath] = node
+	return s.publish(absPath)
+}
+
+// publish publishes the diagnostics of the open document at absPath, from the reports of the last check.
+func (s *Server) publish(absPath string) error {
+	relativePath, err := s.relativePath(absPath)
+	if err != nil {
+		return err
+	}
+	diagnostics := []Diagnostic{}
+	if report, isDocument := s.reports[relativePath]; isDocument {
+		source, err := s.graph.Source(absPath)
+		if err != nil {
+			return err
+		}
+		diagnostics = s.diagnostics(source, report, s.documents)
+	}
+	if err, failed := s.graph.Failures()[relativePath]; failed {
+		// The diagnostics are the ones of the last version that could be parsed
+		diagnostics = append([]Diagnostic{{
+			Severity: severityWarning,
+			Code:     parseErrorCode,
+			Source:   diagnosticSource,
+			Message:  "Could not parse document: " + err.Error(),
+		}}, diagnostics...)
+	}
+	return s.notify("textDocument/publishDiagnostics",
+		publishDiagnosticsParams{URI: s.pathToURI(relativePath), Diagnostics: diagnostics})
+}
+
+// diagnostics returns the diagnostics of a document: dead links and images, links to missing anchors,
+// and whether it is an orphan.
+func (s *Server) diagnostics(
+	source []byte,
+	report checkdoc.NodeReport,
+	documents map[string]checkdoc.LinkGraphNode,
+) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	add := func(destination string, severity int, code string, message string) {
+		for _, offset := range checkdoc.LocateDestination(s.config.Parser, source, destination) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    rangeOf(source, offset, offset+len(destination)),
+				Severity: severity,
+				Code:     code,
+				Source:   diagnosticSource,
+				Message:  message,
+			})
+		}
+	}
+
+	for _, finding := range checkdoc.ListFindings(map[string]checkdoc.NodeReport{report.Node.RelativePath: report}, nil) {
+		if finding.Kind == checkdoc.OrphanFinding {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    rangeOf(source, 0, lineEnd(source, 0)),
+				Severity: severityWarning,
+				Code:     string(finding.Kind),
+				Source:   diagnosticSource,
+				Message:  "Orphan document: " + finding.Message,
+			})
+		}
+	}
+
+	for _, link := range checkdoc.LocalDestinations(s.config.TreeRoot, report.Node) {
+		isDeadLink := slices.Contains(report.DeadLinks, link.Target)
+		if !isDeadLink && !slices.Contains(report.DeadImageLinks, link.Target) {
+			continue
+		}
+		kind := checkdoc.DeadLinkFinding
+		if !isDeadLink {
+			kind = checkdoc.BrokenImageFinding
+		}
+		add(link.Destination, severityError, string(kind),
+			fmt.Sprintf("%s does not exist%s", link.Target, checkdoc.DidYouMean(report.Suggestions[link.Target])))
+	}
+
+	for _, deadAnchor := range checkdoc.FindDeadAnchors(s.config.TreeRoot, report.Node, documents,
+		s.config.ImplicitIndexes) {
+		add(deadAnchor.Destination, severityError, deadAnchorCode,
+			fmt.Sprintf("%s has no heading with anchor #%s", deadAnchor.Target, deadAnchor.Anchor))
+	}
+
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		if a.Range.Start.Line != b.Range.Start.Line {
+			return a.Range.Start.Line - b.Range.Start.Line
+		}
+		return a.Range.Start.Character - b.Range.Start.Character
+	})
+	return diagnostics
+}
+
+// lineEnd returns the offset of the end of the line containing offset.
+func lineEnd(source []byte, offset int) int {
+	for offset < len(source) && source[offset] != '\n' {
+		offset++
+	}
+	return offset
+}
+
+func sortedPaths(versions map[string]int) []string {
+	var paths []string
+	for path := range versions {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
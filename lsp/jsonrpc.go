@@ -0,0 +1,109 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC error codes used by the server
+const (
+	parseError           = -32700
+	methodNotFound       = -32601
+	invalidParams        = -32602
+	serverNotInitialized = -32002
+	requestFailed        = -32803
+)
+
+// message is a JSON-RPC request, notification or response: requests have both an ID and a method,
+// notifications only have a method.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// responseError is the error of a failed request.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads a message framed with a Content-Length header, as LSP clients send them.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			// Content-Type is the only other header, and only utf-8 is supported anyway
+			continue
+		}
+		if contentLength, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("invalid Content-Length %q: %w", value, err)
+		}
+	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	content := make([]byte, contentLength)
+	_, err := io.ReadFull(r, content)
+	return content, err
+}
+
+// writeMessage writes the passed value as JSON, framed with a Content-Length header.
+func writeMessage(w io.Writer, value any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// response builds the response to the request with the passed ID: its result, or its error if it failed.
+func response(id *json.RawMessage, result any, err error) any {
+	if err == nil {
+		return struct {
+			JSONRPC string           `json:"jsonrpc"`
+			ID      *json.RawMessage `json:"id"`
+			Result  any              `json:"result"`
+		}{"2.0", id, result}
+	}
+	rpcErr, ok := err.(*responseError)
+	if !ok {
+		rpcErr = &responseError{Code: requestFailed, Message: err.Error()}
+	}
+	return struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Error   *responseError   `json:"error"`
+	}{"2.0", id, rpcErr}
+}
+
+// notification builds a notification of the passed method.
+func notification(method string, params any) any {
+	return struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{"2.0", method, params}
+}
@@ -0,0 +1,223 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// linkAtPosition is the link under the cursor.
+type linkAtPosition struct {
+	checkdoc.LinkDestination
+	start  int    // Offset of the destination in the source
+	target string // Target of the link, with directories resolved to their implicit index
+	anchor string // The anchor of the link, without '#', empty if it has none
+}
+
+// documentAt returns the source and node of the document of a request, failing if it is not a document.
+func (s *Server) documentAt(uri string) ([]byte, checkdoc.LinkGraphNode, error) {
+	absPath, err := s.uriToPath(uri)
+	if err != nil {
+		return nil, checkdoc.LinkGraphNode{}, err
+	}
+	node, isDocument := s.graph.Node(absPath)
+	if !isDocument {
+		return nil, checkdoc.LinkGraphNode{}, fmt.Errorf("%s is not a document", uri)
+	}
+	source, err := s.graph.Source(absPath)
+	return source, node, err
+}
+
+// linkAt returns the local link whose destination contains the passed offset, if any.
+// Links to anchors of the document itself are included.
+func (s *Server) linkAt(source []byte, node checkdoc.LinkGraphNode, offset int) (linkAtPosition, bool) {
+	links := checkdoc.LocalDestinations(s.config.TreeRoot, node)
+	for _, link := range node.Document.Links {
+		if strings.HasPrefix(link.Destination, "#") {
+			links = append(links, checkdoc.LinkDestination{Destination: link.Destination, Target: node.RelativePath})
+		}
+	}
+	for _, link := range links {
+		for _, start := range checkdoc.LocateDestination(s.config.Parser, source, link.Destination) {
+			if offset < start || offset > start+len(link.Destination) {
+				continue
+			}
+			_, anchor, _ := strings.Cut(link.Destination, "#")
+			return linkAtPosition{
+				LinkDestination: link,
+				start:           start,
+				target:          checkdoc.ResolveImplicitIndex(s.config.TreeRoot, s.config.ImplicitIndexes, link.Target),
+				anchor:          anchor,
+			}, true
+		}
+	}
+	return linkAtPosition{}, false
+}
+
+// definition goes to the target of the link under the cursor, or to the heading its anchor refers to.
+func (s *Server) definition(p textDocumentPositionParams) (*Location, error) {
+	source, node, err := s.documentAt(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	link, found := s.linkAt(source, node, positionToOffset(source, p.Position))
+	if !found {
+		return nil, nil
+	}
+
+	line := 0
+	target, isDocument := s.graph.Node(filepath.Join(s.config.TreeRoot, link.target))
+	if !isDocument {
+		if _, err := os.Stat(filepath.Join(s.config.TreeRoot, link.target)); err != nil {
+			return nil, nil
+		}
+	} else if link.anchor != "" {
+		index := slices.IndexFunc(target.Document.Headings, func(heading markdown.Heading) bool {
+			return strings.EqualFold(heading.Anchor, link.anchor)
+		})
+		if index >= 0 {
+			line = target.Document.Headings[index].Line - 1
+		}
+	}
+	position := Position{Line: line}
+	return &Location{URI: s.pathToURI(link.target), Range: Range{Start: position, End: position}}, nil
+}
+
+// references lists the links to the target of the link under the cursor, or else to the document itself.
+func (s *Server) references(p textDocumentPositionParams) ([]Location, error) {
+	source, node, err := s.documentAt(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	target := node.RelativePath
+	if link, found := s.linkAt(source, node, positionToOffset(source, p.Position)); found {
+		target = link.target
+	}
+
+	backlinks, err := checkdoc.FindBacklinks(s.config.TreeRoot, s.graph.Nodes(), s.config.ImplicitIndexes,
+		s.config.Parser, target)
+	if err != nil {
+		return nil, err
+	}
+	locations := []Location{}
+	located := make(map[checkdoc.Backlink]bool)
+	for _, backlink := range backlinks {
+		// Lines come from the files: the destinations are located again in the sources, which may not be saved.
+		backlink.Line = 0
+		if located[backlink] {
+			continue
+		}
+		located[backlink] = true
+		backlinkSource, err := s.graph.Source(filepath.Join(s.config.TreeRoot, backlink.DocumentPath))
+		if err != nil {
+			return nil, err
+		}
+		for _, start := range checkdoc.LocateDestination(s.config.Parser, backlinkSource, backlink.Destination) {
+			locations = append(locations, Location{
+				URI:   s.pathToURI(backlink.DocumentPath),
+				Range: rangeOf(backlinkSource, start, start+len(backlink.Destination)),
+			})
+		}
+	}
+	return locations, nil
+}
+
+// renameTarget returns what a rename at the passed position moves: the target of the link under the cursor,
+// or else the document itself. The range is the one of the link's destination, or an empty one at the cursor.
+func (s *Server) renameTarget(p textDocumentPositionParams) (string, Range, error) {
+	source, node, err := s.documentAt(p.TextDocument.URI)
+	if err != nil {
+		return "", Range{}, err
+	}
+	offset := positionToOffset(source, p.Position)
+	link, found := s.linkAt(source, node, offset)
+	if !found {
+		return node.RelativePath, rangeOf(source, offset, offset), nil
+	}
+	if _, err := os.Stat(filepath.Join(s.config.TreeRoot, link.target)); err != nil {
+		return "", Range{}, fmt.Errorf("cannot rename %s: %w", link.target, err)
+	}
+	return link.target, rangeOf(source, link.start, link.start+len(link.Destination)), nil
+}
+
+// prepareRename tells what a rename would move: the new name is expected relative to the root, like the placeholder.
+func (s *Server) prepareRename(p textDocumentPositionParams) (*prepareRenameResult, error) {
+	target, targetRange, err := s.renameTarget(p)
+	if err != nil {
+		return nil, err
+	}
+	return &prepareRenameResult{Range: targetRange, Placeholder: target}, nil
+}
+
+// rename moves the target of the link under the cursor, or the document itself, to newName,
+// relative to the root, and updates the links to it like checkdoc mv does.
+func (s *Server) rename(p renameParams) (*workspaceEdit, error) {
+	oldPath, _, err := s.renameTarget(textDocumentPositionParams{TextDocument: p.TextDocument, Position: p.Position})
+	if err != nil {
+		return nil, err
+	}
+	newPath := strings.TrimPrefix(path.Clean("/"+p.NewName), "/")
+	if newPath == "" || newPath == oldPath {
+		return nil, fmt.Errorf("invalid new name %q for %s", p.NewName, oldPath)
+	}
+	if _, err := os.Stat(filepath.Join(s.config.TreeRoot, newPath)); err == nil {
+		return nil, fmt.Errorf("cannot rename %s to %s: it already exists", oldPath, newPath)
+	}
+
+	fixes := checkdoc.PlanMove(s.config.TreeRoot, s.graph.Nodes(), s.config.ImplicitIndexes, oldPath, newPath)
+	edit := &workspaceEdit{DocumentChanges: []any{}}
+	for i := 0; i < len(fixes); {
+		documentPath := fixes[i].DocumentPath
+		var documentFixes []checkdoc.LinkFix
+		for ; i < len(fixes) && fixes[i].DocumentPath == documentPath; i++ {
+			documentFixes = append(documentFixes, fixes[i])
+		}
+		documentEdit, err := s.documentEdit(documentPath, documentFixes)
+		if err != nil {
+			return nil, err
+		}
+		if len(documentEdit.Edits) > 0 {
+			edit.DocumentChanges = append(edit.DocumentChanges, documentEdit)
+		}
+	}
+	// Edits refer to documents by their path before the move
+	edit.DocumentChanges = append(edit.DocumentChanges,
+		renameFile{Kind: "rename", OldURI: s.pathToURI(oldPath), NewURI: s.pathToURI(newPath)})
+	return edit, nil
+}
+
+// documentEdit turns the fixes of a document into edits of the destinations they rewrite.
+func (s *Server) documentEdit(documentPath string, fixes []checkdoc.LinkFix) (textDocumentEdit, error) {
+	absPath := filepath.Join(s.config.TreeRoot, documentPath)
+	source, err := s.graph.Source(absPath)
+	if err != nil {
+		return textDocumentEdit{}, err
+	}
+	documentEdit := textDocumentEdit{
+		TextDocument: versionedTextDocumentIdentifier{URI: s.pathToURI(documentPath)},
+		Edits:        []TextEdit{},
+	}
+	if version, isOpen := s.versions[absPath]; isOpen {
+		documentEdit.TextDocument.Version = &version
+	}
+	edited := make(map[int]bool)
+	for _, fix := range fixes {
+		for _, start := range checkdoc.LocateDestination(s.config.Parser, source, fix.OldDestination) {
+			if edited[start] {
+				continue
+			}
+			edited[start] = true
+			documentEdit.Edits = append(documentEdit.Edits, TextEdit{
+				Range:   rangeOf(source, start, start+len(fix.OldDestination)),
+				NewText: fix.NewDestination,
+			})
+		}
+	}
+	return documentEdit, nil
+}
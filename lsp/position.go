@@ -0,0 +1,57 @@
+package lsp
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// offsetToPosition converts a byte offset in a source to an LSP position, counting characters in UTF-16 code units.
+func offsetToPosition(source []byte, offset int) Position {
+	offset = min(max(offset, 0), len(source))
+	var position Position
+	for i := 0; i < offset; {
+		r, size := utf8.DecodeRune(source[i:])
+		i += size
+		if r == '\n' {
+			position.Line++
+			position.Character = 0
+			continue
+		}
+		position.Character += utf16Length(r)
+	}
+	return position
+}
+
+// positionToOffset converts an LSP position to a byte offset in a source. Positions past the end of a line
+// are clamped to the end of the line, and positions past the end of the source to its length.
+func positionToOffset(source []byte, position Position) int {
+	offset := 0
+	for line := 0; line < position.Line; line++ {
+		next := bytes.IndexByte(source[offset:], '\n')
+		if next < 0 {
+			return len(source)
+		}
+		offset += next + 1
+	}
+	for character := 0; character < position.Character && offset < len(source); {
+		r, size := utf8.DecodeRune(source[offset:])
+		if r == '\n' {
+			break
+		}
+		character += utf16Length(r)
+		offset += size
+	}
+	return offset
+}
+
+// rangeOf returns the range of the passed bytes of a source.
+func rangeOf(source []byte, start int, end int) Range {
+	return Range{Start: offsetToPosition(source, start), End: offsetToPosition(source, end)}
+}
+
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
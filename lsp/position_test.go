@@ -0,0 +1,21 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositions(t *testing.T) {
+	// é takes 2 bytes and 1 UTF-16 code unit, 😀 takes 4 bytes and 2 UTF-16 code units
+	source := []byte("é😀[a](b.md)\nsecond\n")
+
+	assert.Equal(t, Position{Line: 0, Character: 3}, offsetToPosition(source, 6))
+	assert.Equal(t, 6, positionToOffset(source, Position{Line: 0, Character: 3}))
+	assert.Equal(t, Position{Line: 1, Character: 2}, offsetToPosition(source, 18))
+	assert.Equal(t, 18, positionToOffset(source, Position{Line: 1, Character: 2}))
+
+	assert.Equal(t, 15, positionToOffset(source, Position{Line: 0, Character: 100}), "Clamped to the end of the line")
+	assert.Equal(t, len(source), positionToOffset(source, Position{Line: 5, Character: 0}))
+	assert.Equal(t, Position{Line: 2, Character: 0}, offsetToPosition(source, 100))
+}
@@ -0,0 +1,271 @@
+// Package lsp implements a Language Server Protocol server over stdio, giving editors the diagnostics of checkdoc
+// and navigation along the links between documents.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// Config tells the server where the documentation is, and how to find and parse documents.
+type Config struct {
+	TreeRoot         string // Absolute path to the root of the documentation
+	BaseNames        []string
+	Extensions       []string
+	ImplicitIndexes  []string
+	RootDocuments    []string // Documents that need no link to them, relative to the root
+	RespectGitIgnore bool
+	Parser           markdown.Parser
+}
+
+// Server is a language server for the documents of a tree. It keeps the link graph in memory:
+// documents open in the editor are parsed from their unsaved content, other documents are parsed again
+// when they are saved or when the editor reports changes to files.
+type Server struct {
+	config    Config
+	graph     *checkdoc.IncrementalGraph
+	versions  map[string]int                    // Versions of the open documents, by absolute path
+	reports   map[string]checkdoc.NodeReport    // The reports of the last check, by relative path
+	documents map[string]checkdoc.LinkGraphNode // The nodes of the last check, by relative path
+	out       io.Writer
+	shutdown  bool
+}
+
+// NewServer returns a server for the documents of the configured tree, to be started with Run.
+func NewServer(config Config) *Server {
+	return &Server{
+		config:    config,
+		versions:  make(map[string]int),
+		reports:   make(map[string]checkdoc.NodeReport),
+		documents: make(map[string]checkdoc.LinkGraphNode),
+	}
+}
+
+// Run serves the messages read from in, writing responses and notifications to out, until the client
+// asks the server to exit or in is closed. It fails if the client exits without asking for a shutdown first.
+func (s *Server) Run(in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+	for {
+		content, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			if err := writeMessage(out, response(nil, nil, &responseError{Code: parseError, Message: err.Error()})); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit requested before shutdown")
+			}
+			return nil
+		}
+		if msg.ID == nil {
+			if err := s.handleNotification(msg.Method, msg.Params); err != nil {
+				slog.Warn("Could not handle notification", "method", msg.Method, "err", err)
+			}
+			continue
+		}
+		result, err := s.handleRequest(msg.Method, msg.Params)
+		if err := writeMessage(out, response(msg.ID, result, err)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handleRequest(method string, params json.RawMessage) (any, error) {
+	if s.graph == nil && method != "initialize" && method != "shutdown" {
+		return nil, &responseError{Code: serverNotInitialized, Message: "server not initialized"}
+	}
+	switch method {
+	case "initialize":
+		return s.initialize()
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.definition(p)
+	case "textDocument/references":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.references(p)
+	case "textDocument/prepareRename":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.prepareRename(p)
+	case "textDocument/rename":
+		var p renameParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.rename(p)
+	case "textDocument/completion":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.completion(p)
+	default:
+		return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf("method %s not supported", method)}
+	}
+}
+
+func (s *Server) handleNotification(method string, params json.RawMessage) error {
+	if s.graph == nil {
+		return nil
+	}
+	switch method {
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return err
+		}
+		if err := s.setSource(p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text); err != nil {
+			return err
+		}
+		return s.publishDiagnostics()
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return err
+		}
+		if len(p.ContentChanges) == 0 {
+			return nil
+		}
+		// With full synchronization, the last change holds the whole document
+		if err := s.setSource(p.TextDocument.URI, p.TextDocument.Version,
+			p.ContentChanges[len(p.ContentChanges)-1].Text); err != nil {
+			return err
+		}
+		return s.publishDocumentDiagnostics(p.TextDocument.URI)
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return err
+		}
+		return s.closeDocument(p.TextDocument.URI)
+	case "textDocument/didSave", "workspace/didChangeWatchedFiles":
+		if _, _, err := s.graph.Update(); err != nil {
+			return err
+		}
+		return s.publishDiagnostics()
+	default:
+		return nil
+	}
+}
+
+func (s *Server) initialize() (initializeResult, error) {
+	if s.graph == nil {
+		graph, err := checkdoc.NewIncrementalGraph(s.config.TreeRoot, s.config.BaseNames, s.config.Extensions,
+			s.config.RespectGitIgnore, s.config.Parser)
+		if err != nil {
+			return initializeResult{}, fmt.Errorf("could not build the link graph for tree root %s: %w",
+				s.config.TreeRoot, err)
+		}
+		s.graph = graph
+	}
+
+	var result initializeResult
+	result.ServerInfo.Name = "checkdoc"
+	capabilities := &result.Capabilities
+	capabilities.TextDocumentSync.OpenClose = true
+	capabilities.TextDocumentSync.Change = syncFull
+	capabilities.TextDocumentSync.Save = true
+	capabilities.DefinitionProvider = true
+	capabilities.ReferencesProvider = true
+	capabilities.RenameProvider.PrepareProvider = true
+	capabilities.CompletionProvider.TriggerCharacters = []string{"(", "/", "#"}
+	return result, nil
+}
+
+func (s *Server) setSource(uri string, version int, text string) error {
+	absPath, err := s.uriToPath(uri)
+	if err != nil {
+		return err
+	}
+	s.versions[absPath] = version
+	return s.graph.SetSource(absPath, []byte(text))
+}
+
+func (s *Server) closeDocument(uri string) error {
+	absPath, err := s.uriToPath(uri)
+	if err != nil {
+		return err
+	}
+	delete(s.versions, absPath)
+	s.graph.ClearSource(absPath)
+	// Diagnostics are only kept up to date for open documents
+	if err := s.notify("textDocument/publishDiagnostics",
+		publishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}}); err != nil {
+		return err
+	}
+	return s.publishDiagnostics()
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, notification(method, params))
+}
+
+func unmarshalParams(params json.RawMessage, value any) error {
+	if err := json.Unmarshal(params, value); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// uriToPath converts a file URI to an absolute path, which must be within the tree root.
+func (s *Server) uriToPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI %s, only file URIs are", uri)
+	}
+	absPath := filepath.Clean(filepath.FromSlash(parsed.Path))
+	if _, err := s.relativePath(absPath); err != nil {
+		return "", err
+	}
+	return absPath, nil
+}
+
+// relativePath returns the path of absPath relative to the tree root.
+func (s *Server) relativePath(absPath string) (string, error) {
+	relativePath, err := filepath.Rel(s.config.TreeRoot, absPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, "../") {
+		return "", fmt.Errorf("%s is not within the tree root %s", absPath, s.config.TreeRoot)
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+// pathToURI converts a path relative to the tree root to a file URI.
+func (s *Server) pathToURI(relativePath string) string {
+	absPath := filepath.Join(s.config.TreeRoot, relativePath)
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String()
+}
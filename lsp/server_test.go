@@ -0,0 +1,211 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/internal/testtree"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+// session runs the server on the passed messages, and returns what it wrote: results or errors by response ID,
+// and notifications by method.
+func session(t *testing.T, treeRoot string, messages ...string) map[string][]json.RawMessage {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		assert.NoError(t, writeMessage(&in, json.RawMessage(msg)))
+	}
+	parser, err := markdown.NewParser(markdown.BlackfridayBackend)
+	assert.NoError(t, err)
+	server := NewServer(Config{
+		TreeRoot:        treeRoot,
+		Extensions:      []string{".md"},
+		ImplicitIndexes: []string{"README.md"},
+		RootDocuments:   []string{"README.md"},
+		Parser:          parser,
+	})
+	var out bytes.Buffer
+	assert.NoError(t, server.Run(&in, &out))
+
+	written := make(map[string][]json.RawMessage)
+	reader := bufio.NewReader(&out)
+	for reader.Buffered() > 0 || out.Len() > 0 {
+		content, err := readMessage(reader)
+		if !assert.NoError(t, err) {
+			break
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+			Params json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(content, &msg))
+		if msg.Method != "" {
+			written[msg.Method] = append(written[msg.Method], msg.Params)
+		} else if msg.Error != nil {
+			written[string(msg.ID)] = append(written[string(msg.ID)], msg.Error)
+		} else {
+			written[string(msg.ID)] = append(written[string(msg.ID)], msg.Result)
+		}
+	}
+	return written
+}
+
+func TestServer(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":        "# Root\n\n[guide](docs/guide.md)\n",
+		"docs/guide.md":    "# Guide\n\n## Set up\n\nSee [the tools](../tools/).\n",
+		"docs/orphan.md":   "# Orphan\n",
+		"tools/README.md":  "# Tools\n",
+		"tools/install.sh": "",
+	})
+	uri := "file://" + filepath.ToSlash(treeRoot) + "/README.md"
+	text := "# Root\n\n[guide](docs/guide.md#set-up) [gone](docs/gone.md) [anchor](docs/guide.md#nope)\n[t](tools/)\n"
+
+	written := session(t, treeRoot,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+uri+`","version":1,"text":`+
+			quote(text)+`}}}`,
+		// Definition of the anchor link, leading to the heading
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/definition","params":{"textDocument":{"uri":"`+uri+`"},`+
+			`"position":{"line":2,"character":10}}}`,
+		// Completion of the anchors of the guide
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/completion","params":{"textDocument":{"uri":"`+uri+`"},`+
+			`"position":{"line":2,"character":74}}}`,
+		// References to the tools directory's README, from the link under the cursor
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/references","params":{"textDocument":{"uri":"`+uri+`"},`+
+			`"position":{"line":3,"character":5}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"textDocument/rename","params":{"textDocument":{"uri":"`+uri+`"},`+
+			`"position":{"line":2,"character":10},"newName":"guide.md"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"unknown/method","params":{}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[
+		{"range":{"start":{"line":2,"character":37},"end":{"line":2,"character":49}},"severity":1,"code":"dead-link",
+		 "source":"checkdoc","message":"docs/gone.md does not exist"},
+		{"range":{"start":{"line":2,"character":60},"end":{"line":2,"character":78}},"severity":1,"code":"dead-anchor",
+		 "source":"checkdoc","message":"docs/guide.md has no heading with anchor #nope"}
+	]}`, string(written["textDocument/publishDiagnostics"][0]))
+
+	guideURI := "file://" + filepath.ToSlash(treeRoot) + "/docs/guide.md"
+	assert.JSONEq(t, `{"uri":"`+guideURI+`","range":{"start":{"line":2,"character":0},"end":{"line":2,"character":0}}}`,
+		string(written["2"][0]))
+
+	var completions []CompletionItem
+	assert.NoError(t, json.Unmarshal(written["3"][0], &completions))
+	assert.Equal(t, []string{"guide", "set-up"}, completionLabels(completions))
+
+	var references []Location
+	assert.NoError(t, json.Unmarshal(written["4"][0], &references))
+	assert.Equal(t, []Location{
+		{URI: uri, Range: Range{Start: Position{Line: 3, Character: 4}, End: Position{Line: 3, Character: 10}}},
+		{URI: guideURI, Range: Range{Start: Position{Line: 4, Character: 16}, End: Position{Line: 4, Character: 25}}},
+	}, references)
+
+	assert.JSONEq(t, `{"documentChanges":[
+		{"textDocument":{"uri":"`+uri+`","version":1},"edits":[
+			{"range":{"start":{"line":2,"character":8},"end":{"line":2,"character":28}},"newText":"guide.md#set-up"},
+			{"range":{"start":{"line":2,"character":60},"end":{"line":2,"character":78}},"newText":"guide.md#nope"}
+		]},
+		{"textDocument":{"uri":"`+guideURI+`","version":null},"edits":[
+			{"range":{"start":{"line":4,"character":16},"end":{"line":4,"character":25}},"newText":"tools/"}
+		]},
+		{"kind":"rename","oldUri":"`+guideURI+`","newUri":"file://`+filepath.ToSlash(treeRoot)+`/guide.md"}
+	]}`, string(written["5"][0]))
+
+	assert.JSONEq(t, `{"code":-32601,"message":"method unknown/method not supported"}`, string(written["6"][0]))
+	assert.Equal(t, "null", string(written["7"][0]))
+}
+
+func TestServerDidChange(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":     "# Root\n\n[guide](docs/guide.md#set-up)\n",
+		"docs/guide.md": "# Guide\n\n## Set up\n",
+	})
+	uri := "file://" + filepath.ToSlash(treeRoot) + "/README.md"
+	guideURI := "file://" + filepath.ToSlash(treeRoot) + "/docs/guide.md"
+	// A link outside of the tree root fails parsing
+	changed := "[out](../../out.md)\n\n\n[guide](docs/guide.md#set-up)\n"
+
+	written := session(t, treeRoot,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+uri+`","version":1,"text":`+
+			quote("# Root\n\n[guide](docs/guide.md#set-up)\n")+`}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+guideURI+`","version":1,`+
+			`"text":`+quote("# Guide\n\n## Set up\n")+`}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"`+uri+`","version":2},`+
+			`"contentChanges":[{"text":`+quote(changed)+`}]}}`,
+		// Definition of the anchor link, moved down a line in the changed text
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/definition","params":{"textDocument":{"uri":"`+uri+`"},`+
+			`"position":{"line":3,"character":10}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	// Both documents on the first open, then the guide on the second one, then the changed document only
+	diagnostics := written["textDocument/publishDiagnostics"]
+	assert.Len(t, diagnostics, 4)
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[
+		{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"severity":2,"code":"parse-error",
+		 "source":"checkdoc","message":"Could not parse document: failed to normalize relative links in [] from root `+
+		treeRoot+`:relative link ../../out.md points outside of the tree root `+treeRoot+`/ for file README.md"}
+	]}`, string(diagnostics[3]))
+
+	assert.JSONEq(t, `{"uri":"`+guideURI+`","range":{"start":{"line":2,"character":0},"end":{"line":2,"character":0}}}`,
+		string(written["2"][0]), "The changed text should be used, even if it failed to parse")
+}
+
+func TestServerDidChangeSuggestions(t *testing.T) {
+	treeRoot := testtree.Write(t, map[string]string{
+		"README.md":       "# Root\n\n[install](docs/instal.md)\n",
+		"docs/install.md": "# Install\n",
+		"docs/guide.md":   "# Guide\n",
+	})
+	uri := "file://" + filepath.ToSlash(treeRoot) + "/README.md"
+
+	written := session(t, treeRoot,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+uri+`","version":1,"text":`+
+			quote("# Root\n\n[install](docs/instal.md)\n")+`}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"`+uri+`","version":2},`+
+			`"contentChanges":[{"text":`+quote("# Root\n\n[install](docs/instal.md) [guide](docs/gide.md)\n")+`}]}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	diagnostics := written["textDocument/publishDiagnostics"]
+	assert.Len(t, diagnostics, 2)
+	var published publishDiagnosticsParams
+	assert.NoError(t, json.Unmarshal(diagnostics[1], &published))
+	var messages []string
+	for _, diagnostic := range published.Diagnostics {
+		messages = append(messages, diagnostic.Message)
+	}
+	assert.Contains(t, messages, "docs/instal.md does not exist (did you mean docs/install.md?)",
+		"Suggestions should be kept from the last check")
+	assert.Contains(t, messages, "docs/gide.md does not exist", "Suggestions should not be looked for on edits")
+}
+
+func completionLabels(items []CompletionItem) []string {
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func quote(text string) string {
+	quoted, _ := json.Marshal(text)
+	return string(quoted)
+}
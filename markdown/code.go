@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// BlankCode returns a copy of a markdown source where front matter, the content of code blocks, fenced or indented,
+// and of code spans are replaced by spaces, except for line breaks, so that offsets in the returned source
+// still match the original one. Searching the returned source for link syntax does not find code samples.
+// Code is told apart from the rest the way the passed parser does it.
+func BlankCode(parser Parser, source []byte) []byte {
+	// Front matter that does not parse is kept, as it is parsed as markdown, see ParseSource.
+	_, body, _ := ExtractFrontMatter(source)
+	return parser.BlankCode(body)
+}
+
+// BlankCode blanks code the way CommonMark tells it apart from the rest, see the BlankCode function.
+func (p goldmarkParser) BlankCode(source []byte) []byte {
+	blanked := bytes.Clone(source)
+	blank := func(segment text.Segment) {
+		blankRange(blanked, segment.Start, segment.Stop)
+	}
+
+	root := p.md.Parser().Parse(text.NewReader(source))
+	// The walker only returns the errors we return ourselves, and we don't.
+	_ = ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock:
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				blank(lines.At(i))
+			}
+			return ast.WalkSkipChildren, nil
+		case ast.KindCodeSpan:
+			for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+				if textNode, ok := child.(*ast.Text); ok {
+					blank(textNode.Segment)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return blanked
+}
+
+// BlankCode blanks code line by line, see the BlankCode function. Blackfriday does not keep track of
+// where nodes are in the source, so code is told apart from the rest the same way ExtractReferences does it.
+// Fences are blanked along with the code they enclose, and code spans along with their backticks.
+func (blackfridayParser) BlankCode(source []byte) []byte {
+	blanked := bytes.Clone(source)
+	codeBlocks := newCodeBlockTracker()
+	for offset := 0; offset < len(source); {
+		line, _, _ := bytes.Cut(source[offset:], []byte("\n"))
+		if codeBlocks.inCode(string(line)) {
+			blankRange(blanked, offset, offset+len(line))
+		} else {
+			for _, span := range codeSpanMatcher.FindAllIndex(line, -1) {
+				blankRange(blanked, offset+span[0], offset+span[1])
+			}
+		}
+		offset += len(line) + 1
+	}
+	return blanked
+}
+
+// blankRange replaces the bytes of source between start and stop by spaces, except for line breaks.
+func blankRange(source []byte, start int, stop int) {
+	for i := start; i < stop && i < len(source); i++ {
+		if source[i] != '\n' {
+			source[i] = ' '
+		}
+	}
+}
+
+// codeBlockTracker follows, line by line, whether a markdown source is within a code block, fenced or indented.
+type codeBlockTracker struct {
+	inFence string
+	// An indented line starts a code block after a blank line, unless it continues a list item
+	inIndentedCode, inList, afterBlank bool
+}
+
+func newCodeBlockTracker() *codeBlockTracker {
+	return &codeBlockTracker{afterBlank: true}
+}
+
+// inCode tells if the passed line, the next one of the source, belongs to a code block, fences included.
+// Blank lines outside of fenced code blocks are never considered to be code.
+func (t *codeBlockTracker) inCode(line string) bool {
+	if t.inFence == "" {
+		if strings.TrimSpace(line) == "" {
+			t.afterBlank = true
+			return false
+		}
+		indented := indentedCodeMatcher.MatchString(line)
+		if indented && (t.inIndentedCode || (t.afterBlank && !t.inList)) {
+			t.inIndentedCode, t.afterBlank = true, false
+			return true
+		}
+		switch {
+		case listItemMatcher.MatchString(line):
+			t.inList = true
+		case t.afterBlank && !indented:
+			t.inList = false
+		}
+		t.inIndentedCode, t.afterBlank = false, false
+	}
+
+	if fence := fenceMatcher.FindStringSubmatch(line); fence != nil {
+		switch t.inFence {
+		case "":
+			t.inFence = fence[1]
+		case fence[1]:
+			t.inFence = ""
+		}
+		return true
+	}
+	return t.inFence != ""
+}
@@ -0,0 +1,42 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const codeSource = "# Doc\n\nSee [a](docs/a.md) and `[b](docs/b.md)`.\n\n```sh\nexport PAGE=docs/a.md\n```\n\n" +
+	"    <a href=\"docs/a.md\">indented</a>\n\n- item\n\n  [c](docs/c.md)\n"
+
+func TestBlankCode(t *testing.T) {
+	parser, err := NewParser(GFMBackend)
+	assert.NoError(t, err)
+	blanked := string(BlankCode(parser, []byte(codeSource)))
+
+	assert.Equal(t, len(codeSource), len(blanked), "Offsets should be kept")
+	assert.Equal(t, "# Doc\n\nSee [a](docs/a.md) and `"+strings.Repeat(" ", 14)+"`.\n\n```sh\n"+strings.Repeat(" ", 21)+
+		"\n```\n\n"+strings.Repeat(" ", 36)+"\n\n- item\n\n  [c](docs/c.md)\n", blanked)
+}
+
+func TestBlankCodeBlackfriday(t *testing.T) {
+	parser, err := NewParser(BlackfridayBackend)
+	assert.NoError(t, err)
+	blanked := string(BlankCode(parser, []byte(codeSource)))
+
+	assert.Equal(t, len(codeSource), len(blanked), "Offsets should be kept")
+	assert.Equal(t, "# Doc\n\nSee [a](docs/a.md) and "+strings.Repeat(" ", 16)+".\n\n"+strings.Repeat(" ", 5)+"\n"+
+		strings.Repeat(" ", 21)+"\n   \n\n"+strings.Repeat(" ", 36)+"\n\n- item\n\n  [c](docs/c.md)\n", blanked,
+		"Fences and backticks should be blanked along with the code")
+}
+
+func TestBlankCodeFrontMatter(t *testing.T) {
+	source := "---\nrelated: '[a](docs/a.md)'\n---\n\n[b](docs/b.md)\n"
+	for _, backend := range Backends {
+		parser, err := NewParser(backend)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat(" ", 3)+"\n"+strings.Repeat(" ", 25)+"\n"+strings.Repeat(" ", 3)+
+			"\n\n[b](docs/b.md)\n", string(BlankCode(parser, []byte(source))), backend)
+	}
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Front matter delimiters: YAML front matter is enclosed in '---' lines, TOML in '+++' lines.
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// ErrInvalidFrontMatter is wrapped by the errors about front matter that does not parse.
+var ErrInvalidFrontMatter = errors.New("invalid front matter")
+
+// ExtractFrontMatter detects and parses YAML or TOML front matter at the very beginning of a markdown source.
+// It returns the parsed front matter, which is nil if there is none, and the source where the front matter
+// was blanked out: every byte of it is replaced by a space, except for line breaks, so that line numbers
+// and offsets in the returned source still match the original one.
+// Delimited content that does not parse may as well be markdown between two thematic breaks: the source is then
+// returned unchanged, along with an error wrapping ErrInvalidFrontMatter.
+func ExtractFrontMatter(source []byte) (map[string]any, []byte, error) {
+	firstLine, _, _ := bytes.Cut(source, []byte("\n"))
+	delimiter := string(bytes.TrimRight(firstLine, " \t\r"))
+	if delimiter != yamlDelimiter && delimiter != tomlDelimiter {
+		return nil, source, nil
+	}
+
+	end := findClosingDelimiter(source, len(firstLine)+1, delimiter)
+	if end < 0 {
+		// No closing delimiter: this is not front matter, but probably a thematic break.
+		return nil, source, nil
+	}
+	content := source[len(firstLine)+1 : end]
+
+	frontMatter := make(map[string]any)
+	var err error
+	if delimiter == yamlDelimiter {
+		err = yaml.Unmarshal(content, &frontMatter)
+	} else {
+		err = toml.Unmarshal(content, &frontMatter)
+	}
+	if err != nil {
+		return nil, source, fmt.Errorf("%w: %w", ErrInvalidFrontMatter, err)
+	}
+
+	// Skip the closing delimiter line as well
+	closingLineEnd := bytes.IndexByte(source[end:], '\n')
+	if closingLineEnd < 0 {
+		closingLineEnd = len(source)
+	} else {
+		closingLineEnd += end
+	}
+
+	blanked := bytes.Clone(source)
+	for i := 0; i < closingLineEnd; i++ {
+		if blanked[i] != '\n' {
+			blanked[i] = ' '
+		}
+	}
+	return frontMatter, blanked, nil
+}
+
+// findClosingDelimiter returns the offset of the line consisting of the passed delimiter,
+// starting the search at offset start, or -1 if there is no such line.
+func findClosingDelimiter(source []byte, start int, delimiter string) int {
+	offset := start
+	for offset < len(source) {
+		line, _, _ := bytes.Cut(source[offset:], []byte("\n"))
+		if string(bytes.TrimRight(line, " \t\r")) == delimiter {
+			return offset
+		}
+		offset += len(line) + 1
+	}
+	return -1
+}
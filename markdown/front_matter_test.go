@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractFrontMatterYAML(t *testing.T) {
+	source := []byte("---\ntitle: Install\nowner: [\"team-docs\"]\n---\n# Install\n\n[a link](docs/a.md)\n")
+	frontMatter, body, err := ExtractFrontMatter(source)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"title": "Install", "owner": []any{"team-docs"}}, frontMatter)
+	assert.Equal(t, len(source), len(body), "Offsets should be kept")
+	assert.Equal(t, strings.Repeat(" ", 3)+"\n"+strings.Repeat(" ", 14)+"\n"+strings.Repeat(" ", 20)+"\n"+
+		strings.Repeat(" ", 3)+"\n# Install\n\n[a link](docs/a.md)\n", string(body))
+}
+
+func TestExtractFrontMatterTOML(t *testing.T) {
+	source := []byte("+++\ntitle = \"Install\"\nstatus = \"draft\"\n+++\n# Install\n")
+	frontMatter, body, err := ExtractFrontMatter(source)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"title": "Install", "status": "draft"}, frontMatter)
+	assert.Equal(t, "# Install\n", strings.TrimLeft(string(body), " \n"))
+	assert.Equal(t, 4, strings.Count(string(body), "\n")-1, "Line breaks should be kept")
+}
+
+func TestExtractFrontMatterNone(t *testing.T) {
+	for _, source := range []string{
+		"# No front matter\n",
+		"---\nA thematic break without a closing one\n",
+		"Some text\n---\ntitle: not front matter\n---\n",
+		"",
+	} {
+		frontMatter, body, err := ExtractFrontMatter([]byte(source))
+		assert.NoError(t, err)
+		assert.Nil(t, frontMatter)
+		assert.Equal(t, source, string(body))
+	}
+}
+
+func TestExtractFrontMatterInvalid(t *testing.T) {
+	for _, source := range []string{
+		"---\ntitle: [unclosed\n---\n# Title\n",
+		"+++\ntitle = unquoted\n+++\n# Title\n",
+	} {
+		frontMatter, body, err := ExtractFrontMatter([]byte(source))
+		assert.ErrorIs(t, err, ErrInvalidFrontMatter)
+		assert.Nil(t, frontMatter)
+		assert.Equal(t, source, string(body), "Content that does not parse should be left as markdown")
+	}
+}
+
+func TestParseFileFrontMatter(t *testing.T) {
+	for _, backend := range Backends {
+		doc := parseTestFile(t, backend, "test-front-matter.md-ext")
+		assert.Equal(t, map[string]any{"title": "Front Matter", "owner": "team-docs"}, doc.FrontMatter)
+		assert.Equal(t, []string{"docs/body.md", "docs/ref.md"}, destinations(doc.Links))
+		assert.Equal(t, []ReferenceDefinition{{Label: "ref", Destination: "docs/ref.md", Line: 9}},
+			doc.References.Definitions, "Line numbers should match the original file")
+		assert.Equal(t, 5, doc.Headings[0].Line, "Line numbers should match the original file")
+	}
+}
+
+func TestParseFileThematicBreaks(t *testing.T) {
+	for _, backend := range Backends {
+		parser, err := NewParser(backend)
+		assert.NoError(t, err)
+		doc, _, err := ParseFile(parser, filepath.Join(getTestDir(), "test-thematic-breaks.md-ext"))
+		assert.ErrorIs(t, err, ErrInvalidFrontMatter, "Delimited content that does not parse should be reported")
+		assert.Nil(t, doc.FrontMatter)
+		assert.Equal(t, []string{"docs/between.md"}, destinations(doc.Links), "It should be parsed as markdown")
+		assert.Equal(t, "Thematic Breaks", doc.Title)
+	}
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// goldmarkParser is a CommonMark compliant parser, with the GitHub Flavored Markdown extensions
+// (tables, strikethrough, task lists and autolinks).
+type goldmarkParser struct {
+	md goldmark.Markdown
+}
+
+func newGoldmarkParser() goldmarkParser {
+	return goldmarkParser{md: goldmark.New(goldmark.WithExtensions(extension.GFM))}
+}
+
+func (p goldmarkParser) Parse(source []byte) Document {
+	var doc Document
+	root := p.md.Parser().Parse(text.NewReader(source))
+	// The walker only returns the errors we return ourselves, and we don't.
+	_ = ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Link:
+			doc.Links = append(doc.Links, Link{Destination: string(n.Destination)})
+		case *ast.AutoLink:
+			destination := string(n.URL(source))
+			if n.AutoLinkType == ast.AutoLinkEmail {
+				destination = "mailto:" + destination
+			}
+			doc.Links = append(doc.Links, Link{Destination: destination})
+		case *ast.Image:
+			doc.Images = append(doc.Images, Link{Destination: string(n.Destination)})
+		case *ast.HTMLBlock:
+			rawHTML := linesValue(n.Lines(), source)
+			if n.HasClosure() {
+				rawHTML = append(rawHTML, n.ClosureLine.Value(source)...)
+			}
+			doc.Links = append(doc.Links, extractHTMLLinks(rawHTML, htmlLinkAttributes)...)
+			doc.Images = append(doc.Images, extractHTMLLinks(rawHTML, htmlImageAttributes)...)
+		case *ast.RawHTML:
+			rawHTML := linesValue(n.Segments, source)
+			doc.Links = append(doc.Links, extractHTMLLinks(rawHTML, htmlLinkAttributes)...)
+			doc.Images = append(doc.Images, extractHTMLLinks(rawHTML, htmlImageAttributes)...)
+		}
+		// We want to visit every node.
+		return ast.WalkContinue, nil
+	})
+	return doc
+}
+
+func linesValue(lines *text.Segments, source []byte) []byte {
+	var value []byte
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		value = append(value, segment.Value(source)...)
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// htmlLinkAttributes lists, per tag, the attributes that contain links to other documents.
+var htmlLinkAttributes = map[string][]string{
+	"a": {"href"},
+}
+
+// htmlImageAttributes lists, per tag, the attributes that contain links to images.
+var htmlImageAttributes = map[string][]string{
+	"img":    {"src", "srcset"},
+	"source": {"srcset"},
+}
+
+var htmlCommentMatcher = regexp.MustCompile(`(?s)<!--.*?-->`)
+var htmlTagMatcher = regexp.MustCompile(`(?i)<([a-z][a-z0-9]*)\b([^>]*)>`)
+var htmlAttributeMatcher = regexp.MustCompile(`(?i)([a-z][a-z0-9-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// extractHTMLLinks searches raw html, as found in HTMLBlock and HTMLSpan nodes, for the passed tag attributes
+// and returns their values as links.
+// This is done with regular expressions, which is good enough for the kind of HTML that lives in markdown files.
+func extractHTMLLinks(rawHTML []byte, tagAttributes map[string][]string) []Link {
+	var links []Link
+	withoutComments := htmlCommentMatcher.ReplaceAll(rawHTML, nil)
+	for _, tag := range htmlTagMatcher.FindAllSubmatch(withoutComments, -1) {
+		wantedAttributes, ok := tagAttributes[strings.ToLower(string(tag[1]))]
+		if !ok {
+			continue
+		}
+		for _, attribute := range htmlAttributeMatcher.FindAllSubmatch(tag[2], -1) {
+			name := strings.ToLower(string(attribute[1]))
+			if !slices.Contains(wantedAttributes, name) {
+				continue
+			}
+			// Only one of the quoted, single quoted or unquoted groups will have matched.
+			value := html.UnescapeString(string(attribute[2]) + string(attribute[3]) + string(attribute[4]))
+			for _, destination := range splitAttributeValue(name, value) {
+				links = append(links, Link{Destination: destination})
+			}
+		}
+	}
+	return links
+}
+
+// splitAttributeValue returns the links contained in an attribute value. This is the value itself,
+// except for srcset which holds a comma separated list of links, each one optionally followed by a descriptor.
+func splitAttributeValue(name string, value string) []string {
+	if name != "srcset" {
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(value)}
+	}
+	var destinations []string
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 {
+			destinations = append(destinations, fields[0])
+		}
+	}
+	return destinations
+}
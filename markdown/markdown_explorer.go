@@ -12,6 +12,18 @@ import (
 var urlPrefixMatcher = regexp.MustCompile(`://`)
 var mailToMatcher = regexp.MustCompile(`^mailto:`)
 var sameFileAnchorMatcher = regexp.MustCompile(`^#`)
+var dataURIMatcher = regexp.MustCompile(`^data:`)
+
+// blackfridayParser is the original parser backend, relying on blackfriday with the Autolink extension.
+type blackfridayParser struct{}
+
+func (blackfridayParser) Parse(source []byte) Document {
+	ast := ParseToAst(source)
+	return Document{
+		Links:  ExtractAllLinks(ast),
+		Images: ExtractAllImages(ast),
+	}
+}
 
 // ParseFileToAst parses a file living at the path specified at marcdownFile
 // and returns an abstract syntax tree
@@ -20,20 +32,46 @@ func ParseFileToAst(markdownFile string) (*blackfriday.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	return ParseToAst(input), nil
+}
+
+// ParseToAst parses the passed markdown source and returns an abstract syntax tree
+func ParseToAst(input []byte) *blackfriday.Node {
 	parser := blackfriday.New(blackfriday.WithExtensions(blackfriday.Autolink))
 
-	return parser.Parse(input), nil
+	return parser.Parse(input)
 }
 
 // ExtractAllLinks will extract all links from the passed ast.
-func ExtractAllLinks(ast *blackfriday.Node) []blackfriday.LinkData {
-	var links []blackfriday.LinkData
+// This includes links found in raw HTML, ie, the href of <a> tags.
+func ExtractAllLinks(ast *blackfriday.Node) []Link {
+	return extractNodesOfType(ast, blackfriday.Link, htmlLinkAttributes)
+}
+
+// ExtractAllImages will extract all images from the passed ast, ie, things like ![alt](path/to/image.png).
+// Images are kept apart from links, as they are not expected to point to other documents.
+// This includes images found in raw HTML, ie, the src and srcset of <img> and <source> tags.
+func ExtractAllImages(ast *blackfriday.Node) []Link {
+	return extractNodesOfType(ast, blackfriday.Image, htmlImageAttributes)
+}
+
+func extractNodesOfType(
+	ast *blackfriday.Node,
+	nodeType blackfriday.NodeType,
+	htmlAttributes map[string][]string,
+) []Link {
+	var links []Link
 	ast.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
 		// The visitor is called twice: once when the node is first visited, with entering = 'true',
 		// then again once all the children are done, with entering = 'false'.
 		// We check for links and collect them upon first visit.
-		if entering && node.Type == blackfriday.Link {
-			links = append(links, node.LinkData)
+		if entering && node.Type == nodeType {
+			links = append(links, Link{Destination: string(node.LinkData.Destination)})
+		}
+		// Raw HTML is only available as text, we need to look for links in it ourselves.
+		if entering && (node.Type == blackfriday.HTMLBlock || node.Type == blackfriday.HTMLSpan) {
+			links = append(links, extractHTMLLinks(node.Literal, htmlAttributes)...)
 		}
 		// We want to visit every node.
 		return blackfriday.GoToNext
@@ -46,14 +84,15 @@ func ExtractAllLinks(ast *blackfriday.Node) []blackfriday.LinkData {
 // Links of the form "/absolute-link", "../sibling-dir/something", "sub-dir/something", nothing else.
 // Note on anchors: links pointing to anchors in the same file will not be returned. Links pointing to other files
 // while also containing an anchor (ie, in the form <path_to_file>#<anchor-name> are returned.
-func FilterLocalLinks(links []blackfriday.LinkData) []blackfriday.LinkData {
-	var localLinks []blackfriday.LinkData
+func FilterLocalLinks(links []Link) []Link {
+	var localLinks []Link
 	for _, link := range links {
 		// We eliminate links starting with something like <prefix>://, ie http://, ftp://,
-		// or with a mailto:
-		if urlPrefixMatcher.Match(link.Destination) ||
-			mailToMatcher.Match(link.Destination) ||
-			sameFileAnchorMatcher.Match(link.Destination) {
+		// with a mailto: or inlined data (mostly used for images)
+		if urlPrefixMatcher.MatchString(link.Destination) ||
+			mailToMatcher.MatchString(link.Destination) ||
+			dataURIMatcher.MatchString(link.Destination) ||
+			sameFileAnchorMatcher.MatchString(link.Destination) {
 			continue
 		}
 		// At this point we assume the link to be local: any corner cases will blow up somewhere else
@@ -58,3 +58,63 @@ func TestFilterLocalLinks(t *testing.T) {
 	assert.Equal(t, "../sibling", string(localLinks[4].Destination))
 	assert.Equal(t, "./sub-dir", string(localLinks[5].Destination))
 }
+
+func TestExtractAllImages(t *testing.T) {
+	ast := getTestAst("test-images.md-ext")
+	images := ExtractAllImages(ast)
+
+	assert.Equal(t, 4, len(images))
+	assert.Equal(t, "img/diagram.png", string(images[0].Destination))
+	assert.Equal(t, "https://open.ch/logo.png", string(images[1].Destination))
+	assert.Equal(t, "/img/badge.svg", string(images[2].Destination))
+	assert.Equal(t, "data:image/png;base64,iVBORw0KGgo=", string(images[3].Destination))
+
+	links := ExtractAllLinks(ast)
+	assert.Equal(t, 1, len(links), "Images should not be extracted as links")
+	assert.Equal(t, "relative/badge-target", string(links[0].Destination))
+}
+
+func TestFilterLocalImages(t *testing.T) {
+	ast := getTestAst("test-images.md-ext")
+	localImages := FilterLocalLinks(ExtractAllImages(ast))
+
+	assert.Equal(t, 2, len(localImages))
+	assert.Equal(t, "img/diagram.png", string(localImages[0].Destination))
+	assert.Equal(t, "/img/badge.svg", string(localImages[1].Destination))
+}
+
+func TestExtractHTMLLinks(t *testing.T) {
+	ast := getTestAst("test-html.md-ext")
+
+	assert.Equal(t, []string{"docs/overview.md", "/from/root.md", "https://open.ch", "docs/with&entity.md"},
+		destinations(ExtractAllLinks(ast)))
+	assert.Equal(t, []string{"img/logo.png", "img/dark.png", "img/dark@2x.png", "img/light.png"},
+		destinations(ExtractAllImages(ast)))
+}
+
+func TestExtractReferences(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join(getTestDir(), "test-references.md-ext"))
+	assert.NoError(t, err)
+
+	refs := ExtractReferences(source)
+
+	assert.Equal(t, []ReferenceDefinition{
+		{Label: "install", Destination: "docs/install.md", Line: 14},
+		{Label: "collapsed one", Destination: "docs/collapsed.md", Line: 15},
+		{Label: "shortcut", Destination: "docs/shortcut.md", Line: 16},
+		{Label: "unused", Destination: "docs/unused.md", Line: 17},
+		{Label: "install", Destination: "docs/install-again.md", Line: 18},
+	}, refs.Definitions)
+	assert.Equal(t, []ReferenceUsage{
+		{Label: "install", Line: 3},
+		{Label: "collapsed one", Line: 3},
+		{Label: "shortcut", Line: 3},
+		{Label: "nowhere", Line: 5},
+		{Label: "shortcut", Line: 21},
+		{Label: "install", Line: 28},
+	}, refs.Usages, "Indented code and undefined indexes should be skipped, unlike indented list items")
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	assert.Equal(t, "some label", NormalizeLabel("  Some\t LABEL "))
+}
@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// BlackfridayBackend parses markdown with blackfriday, with the Autolink extension enabled.
+	BlackfridayBackend = "blackfriday"
+	// GFMBackend parses markdown following the CommonMark spec with GitHub Flavored Markdown extensions,
+	// which matches how GitHub renders documents.
+	GFMBackend = "gfm"
+)
+
+// Backends lists the names of the available parser backends.
+var Backends = []string{BlackfridayBackend, GFMBackend}
+
+// Link is a link or image destination found in a markdown document, as written in the document.
+type Link struct {
+	Destination string
+}
+
+// Document holds what we care about in a parsed markdown document, independently of the parser backend.
+type Document struct {
+	Links       []Link         // All links, including autolinks and links found in raw HTML
+	Images      []Link         // All images, including the ones found in raw HTML
+	References  References     // Reference definitions and reference-style links
+	FrontMatter map[string]any // The parsed YAML or TOML front matter, nil if the document has none
+	Title       string         // The text of the first heading, empty if the document has none
+	Headings    []Heading      // All headings, in order
+}
+
+// Parser parses markdown sources into Documents.
+type Parser interface {
+	Parse(source []byte) Document
+	// BlankCode returns a copy of the source where code is replaced by spaces, see the BlankCode function.
+	BlankCode(source []byte) []byte
+}
+
+// NewParser returns a parser using the named backend, which must be one of Backends.
+func NewParser(backend string) (Parser, error) {
+	switch backend {
+	case BlackfridayBackend:
+		return blackfridayParser{}, nil
+	case GFMBackend:
+		return newGoldmarkParser(), nil
+	default:
+		return nil, fmt.Errorf("unknown markdown parser backend %q, expected one of %v", backend, Backends)
+	}
+}
+
+// ParseFile reads and parses the markdown file at the passed path with the passed parser, see ParseSource.
+// It returns the parsed document along with the file's source.
+func ParseFile(parser Parser, markdownFile string) (Document, []byte, error) {
+	source, err := os.ReadFile(markdownFile)
+	if err != nil {
+		return Document{}, nil, err
+	}
+	doc, err := ParseSource(parser, source)
+	return doc, source, err
+}
+
+// ParseSource parses a markdown source with the passed parser.
+// Front matter is parsed on its own and left out of the markdown, so that it does not end up in
+// the document's links. If it does not parse, the whole source is parsed as markdown, and the document
+// is returned along with an error wrapping ErrInvalidFrontMatter.
+func ParseSource(parser Parser, source []byte) (Document, error) {
+	frontMatter, body, err := ExtractFrontMatter(source)
+
+	doc := parser.Parse(body)
+	doc.References = ExtractReferences(body)
+	doc.FrontMatter = frontMatter
+	doc.Headings = ExtractHeadings(body)
+	if len(doc.Headings) > 0 {
+		doc.Title = doc.Headings[0].Text
+	}
+	return doc, err
+}
@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTestFile(t *testing.T, backend string, testFileName string) Document {
+	t.Helper()
+	parser, err := NewParser(backend)
+	assert.NoError(t, err)
+	doc, source, err := ParseFile(parser, filepath.Join(getTestDir(), testFileName))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, source)
+	return doc
+}
+
+func destinations(links []Link) []string {
+	var toRet []string
+	for _, link := range links {
+		toRet = append(toRet, link.Destination)
+	}
+	return toRet
+}
+
+func TestNewParser(t *testing.T) {
+	for _, backend := range Backends {
+		parser, err := NewParser(backend)
+		assert.NoError(t, err)
+		assert.NotNil(t, parser)
+	}
+
+	parser, err := NewParser("not-a-backend")
+	assert.Error(t, err)
+	assert.Nil(t, parser)
+}
+
+func TestParseFileNotExisting(t *testing.T) {
+	parser, err := NewParser(GFMBackend)
+	assert.NoError(t, err)
+	_, _, err = ParseFile(parser, filepath.Join(getTestDir(), "not-existing.md"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestBlackfridayBackend(t *testing.T) {
+	doc := parseTestFile(t, BlackfridayBackend, "test-file.md-ext")
+	assert.Equal(t, 11, len(doc.Links))
+	assert.Empty(t, doc.Images)
+}
+
+func TestGFMBackend(t *testing.T) {
+	doc := parseTestFile(t, GFMBackend, "test-file.md-ext")
+
+	// Unlike blackfriday, the link in the code block is not picked up.
+	assert.Equal(t, []string{
+		"https://google.ch",
+		"https://open.ch",
+		"relative/internal",
+		"/absolute/internal",
+		"nested/relative",
+		"/nested/absolute",
+		"../sibling",
+		"./sub-dir",
+		"mailto:julien@sqooba.io",
+		"#anchor-id",
+	}, destinations(doc.Links))
+	assert.Equal(t, []string{
+		"relative/internal",
+		"/absolute/internal",
+		"nested/relative",
+		"/nested/absolute",
+		"../sibling",
+		"./sub-dir",
+	}, destinations(FilterLocalLinks(doc.Links)))
+}
+
+func TestGFMBackendImagesAndHTML(t *testing.T) {
+	doc := parseTestFile(t, GFMBackend, "test-images.md-ext")
+	assert.Equal(t, []string{"relative/badge-target"}, destinations(doc.Links))
+	assert.Equal(t, []string{
+		"img/diagram.png",
+		"https://open.ch/logo.png",
+		"/img/badge.svg",
+		"data:image/png;base64,iVBORw0KGgo=",
+	}, destinations(doc.Images))
+
+	doc = parseTestFile(t, GFMBackend, "test-html.md-ext")
+	assert.Equal(t, []string{"docs/overview.md", "/from/root.md", "https://open.ch", "docs/with&entity.md"},
+		destinations(doc.Links))
+	assert.Equal(t, []string{"img/logo.png", "img/dark.png", "img/dark@2x.png", "img/light.png"},
+		destinations(doc.Images))
+}
+
+func TestGFMBackendReferences(t *testing.T) {
+	doc := parseTestFile(t, GFMBackend, "test-references.md-ext")
+	// The first definition of a label wins, and collapsed references are resolved.
+	assert.Equal(t, []string{"docs/install.md", "docs/collapsed.md", "docs/shortcut.md", "inline/link.md",
+		"docs/shortcut.md", "docs/install.md"}, destinations(doc.Links))
+}
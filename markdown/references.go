@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ReferenceDefinition is a link reference definition, ie, a line of the form `[label]: destination`.
+type ReferenceDefinition struct {
+	Label       string // The label, normalized so that it can be compared to usages
+	Destination string // Where the definition points to
+	Line        int    // Line of the definition, starting at 1
+}
+
+// ReferenceUsage is a reference-style link, ie, something of the form `[text][label]` or `[label][]`.
+// Shortcut references (`[label]`), and full references right after a word or a closing bracket
+// (as in `a[i][j]`), are only considered when a matching definition exists,
+// as they can't be told apart from text between brackets otherwise.
+type ReferenceUsage struct {
+	Label string // The label, normalized so that it can be compared to definitions
+	Line  int    // Line of the usage, starting at 1
+}
+
+// References holds the reference definitions and usages found in a markdown document, in order of appearance.
+type References struct {
+	Definitions []ReferenceDefinition
+	Usages      []ReferenceUsage
+}
+
+var fenceMatcher = regexp.MustCompile("^ {0,3}(```|~~~)")
+var indentedCodeMatcher = regexp.MustCompile("^(    |\t)")
+var listItemMatcher = regexp.MustCompile(`^ {0,3}([-+*]|[0-9]{1,9}[.)])( |\t|$)`)
+var codeSpanMatcher = regexp.MustCompile("`+[^`]*`+")
+var definitionMatcher = regexp.MustCompile(`^ {0,3}\[((?:[^\]\\]|\\.)+)\]:\s*(<[^>]*>|\S+)`)
+var fullReferenceMatcher = regexp.MustCompile(`\[((?:[^\]\\]|\\.)*)\]\[((?:[^\]\\]|\\.)*)\]`)
+var shortcutReferenceMatcher = regexp.MustCompile(`\[((?:[^\]\\]|\\.)+)\]([^(:]|$)`)
+
+// ExtractReferences scans the raw markdown source for reference definitions and reference-style links.
+// The parser resolves references on its own and does not keep track of them, which is why we
+// need to work on the source. Code blocks, fenced or indented, and code spans are skipped.
+// Footnotes (`[^note]`) are not considered to be references.
+func ExtractReferences(source []byte) References {
+	var refs References
+	var candidates []ReferenceUsage
+
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(source)+1)
+	codeBlocks := newCodeBlockTracker()
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if codeBlocks.inCode(line) {
+			continue
+		}
+
+		if definition := definitionMatcher.FindStringSubmatch(line); definition != nil {
+			if !strings.HasPrefix(definition[1], "^") {
+				refs.Definitions = append(refs.Definitions, ReferenceDefinition{
+					Label:       NormalizeLabel(definition[1]),
+					Destination: strings.Trim(definition[2], "<>"),
+					Line:        lineNumber,
+				})
+			}
+			continue
+		}
+
+		line = codeSpanMatcher.ReplaceAllString(line, "")
+		for _, match := range fullReferenceMatcher.FindAllStringSubmatchIndex(line, -1) {
+			label := line[match[4]:match[5]]
+			if label == "" {
+				// Collapsed reference, `[label][]`
+				label = line[match[2]:match[3]]
+			}
+			if strings.HasPrefix(label, "^") {
+				continue
+			}
+			usage := ReferenceUsage{Label: NormalizeLabel(label), Line: lineNumber}
+			if match[0] > 0 && isIndexed(line[match[0]-1]) {
+				// Most likely an index, as in `a[i][j]`
+				candidates = append(candidates, usage)
+			} else {
+				refs.Usages = append(refs.Usages, usage)
+			}
+		}
+		line = fullReferenceMatcher.ReplaceAllString(line, "")
+		for _, usage := range shortcutReferenceMatcher.FindAllStringSubmatch(line, -1) {
+			candidates = append(candidates, ReferenceUsage{Label: NormalizeLabel(usage[1]), Line: lineNumber})
+		}
+	}
+
+	defined := make(map[string]bool)
+	for _, definition := range refs.Definitions {
+		defined[definition.Label] = true
+	}
+	for _, candidate := range candidates {
+		if defined[candidate.Label] {
+			refs.Usages = append(refs.Usages, candidate)
+		}
+	}
+	slices.SortStableFunc(refs.Usages, func(a, b ReferenceUsage) int {
+		return a.Line - b.Line
+	})
+
+	return refs
+}
+
+// isIndexed tells if the passed character, found right before brackets, makes them look like an index.
+func isIndexed(before byte) bool {
+	return before == '_' || before == ']' || before == ')' ||
+		('a' <= before && before <= 'z') || ('A' <= before && before <= 'Z') || ('0' <= before && before <= '9')
+}
+
+// NormalizeLabel normalizes a reference label the way markdown does when matching usages to definitions:
+// labels are case insensitive, and consecutive whitespaces are considered as a single space.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
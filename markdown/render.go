@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// htmlRenderer renders GitHub Flavored Markdown, keeping the raw HTML of documents.
+var htmlRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// RenderHTML renders a markdown source as HTML, close to how GitHub does: front matter is left out,
+// and headings get the anchors ExtractHeadings gives them as IDs, so that links to anchors work.
+// Front matter that does not parse is rendered as markdown, the way ParseSource parses it.
+// Raw HTML is kept as is: the output is only meant to preview trusted documents.
+func RenderHTML(source []byte) ([]byte, error) {
+	_, body, _ := ExtractFrontMatter(source)
+	var out bytes.Buffer
+	context := parser.NewContext(parser.WithIDs(headingIDs{anchors: make(anchorSet)}))
+	if err := htmlRenderer.Convert(body, &out, parser.WithContext(context)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// headingIDs generates the IDs of headings from their text, like ExtractHeadings generates anchors.
+type headingIDs struct {
+	anchors anchorSet
+}
+
+func (ids headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	return []byte(ids.anchors.add(headingAnchor(string(value))))
+}
+
+func (ids headingIDs) Put(value []byte) {
+	ids.anchors.add(string(value))
+}
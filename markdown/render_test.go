@@ -0,0 +1,38 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTML(t *testing.T) {
+	source := []byte("---\ntitle: Guide\n---\n# Set up [the tools](tools/)\n\n## Usage\n\n## Usage\n\n" +
+		"| a |\n|---|\n| b |\n\n<details>raw</details>\n")
+
+	rendered, err := RenderHTML(source)
+	assert.NoError(t, err)
+	assert.Equal(t, `<h1 id="set-up-the-tools">Set up <a href="tools/">the tools</a></h1>
+<h2 id="usage">Usage</h2>
+<h2 id="usage-1">Usage</h2>
+<table>
+<thead>
+<tr>
+<th>a</th>
+</tr>
+</thead>
+<tbody>
+<tr>
+<td>b</td>
+</tr>
+</tbody>
+</table>
+<details>raw</details>
+`, string(rendered))
+}
+
+func TestRenderHTMLInvalidFrontMatter(t *testing.T) {
+	rendered, err := RenderHTML([]byte("---\n\nBetween [two](two.md) thematic breaks\n\n---\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<hr>\n<p>Between <a href=\"two.md\">two</a> thematic breaks</p>\n<hr>\n", string(rendered))
+}
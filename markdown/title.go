@@ -0,0 +1,112 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var atxHeadingMatcher = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+var setextUnderlineMatcher = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+var inlineLinkMatcher = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// Heading is a heading of a markdown document.
+type Heading struct {
+	Text   string // The text of the heading, as written
+	Level  int    // From 1 to 6
+	Line   int    // The line of the heading's text, starting at 1
+	Anchor string // The anchor GitHub generates for the heading, without '#'
+}
+
+// ExtractTitle returns the text of the first heading of a markdown source, or an empty string if it has none.
+// Both ATX (`# Title`) and setext (`Title` underlined with `===` or `---`) headings are considered,
+// fenced code blocks are skipped. The text of the heading is returned as written.
+func ExtractTitle(source []byte) string {
+	headings := ExtractHeadings(source)
+	if len(headings) == 0 {
+		return ""
+	}
+	return headings[0].Text
+}
+
+// ExtractHeadings returns the headings of a markdown source, in order, found like ExtractTitle does.
+// Anchors are generated the way GitHub does: duplicate anchors get a numbered suffix.
+func ExtractHeadings(source []byte) []Heading {
+	var headings []Heading
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(source)+1)
+	inFence := ""
+	previous := ""
+	lineNumber := 0
+	anchors := make(anchorSet)
+	addHeading := func(text string, level int, line int) {
+		headings = append(headings, Heading{Text: text, Level: level, Line: line, Anchor: anchors.add(headingAnchor(text))})
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+
+		if fence := fenceMatcher.FindStringSubmatch(line); fence != nil {
+			switch inFence {
+			case "":
+				inFence = fence[1]
+			case fence[1]:
+				inFence = ""
+			}
+			previous = ""
+			continue
+		}
+		if inFence != "" {
+			continue
+		}
+
+		if heading := atxHeadingMatcher.FindStringSubmatch(line); heading != nil && heading[2] != "" {
+			addHeading(heading[2], len(heading[1]), lineNumber)
+			previous = ""
+			continue
+		}
+		if underline := setextUnderlineMatcher.FindStringSubmatch(line); underline != nil && previous != "" {
+			level := 1
+			if underline[1][0] == '-' {
+				level = 2
+			}
+			addHeading(previous, level, lineNumber-1)
+			previous = ""
+			continue
+		}
+		previous = strings.TrimSpace(line)
+	}
+	return headings
+}
+
+// anchorSet counts the anchors given to the headings of a document.
+type anchorSet map[string]int
+
+// add records an anchor, returning it with a numbered suffix if it was already given, like GitHub does.
+func (anchors anchorSet) add(anchor string) string {
+	if count := anchors[anchor]; count > 0 {
+		anchors[anchor]++
+		anchor = fmt.Sprintf("%s-%d", anchor, count)
+	}
+	anchors[anchor]++
+	return anchor
+}
+
+// headingAnchor turns the text of a heading into an anchor like GitHub does: it is lower cased, links are replaced
+// by their text, punctuation other than hyphens and underscores is dropped, and spaces become hyphens.
+func headingAnchor(text string) string {
+	text = inlineLinkMatcher.ReplaceAllString(text, "$1")
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractTitle(t *testing.T) {
+	assert.Equal(t, "Getting started", ExtractTitle([]byte("Intro text\n\n## Getting started ##\n\n# Other\n")))
+	assert.Equal(t, "Setext title", ExtractTitle([]byte("\nSetext title\n============\n\n# Other\n")))
+	assert.Equal(t, "Real", ExtractTitle([]byte("```\n# Not a title\n```\n\nText\n\n---\n\n# Real\n")))
+	assert.Equal(t, "", ExtractTitle([]byte("#hashtag\n\nNo heading here\n")))
+	assert.Equal(t, "", ExtractTitle([]byte("")))
+}
+
+func TestExtractHeadings(t *testing.T) {
+	source := "# Getting Started!\n\nText\n\nSetup & [Install](install.md)\n---\n\n```\n# Not a heading\n```\n" +
+		"### Getting started ###\n## snake_case, `code` and Ünïcode\n"
+	assert.Equal(t, []Heading{
+		{Text: "Getting Started!", Level: 1, Line: 1, Anchor: "getting-started"},
+		{Text: "Setup & [Install](install.md)", Level: 2, Line: 5, Anchor: "setup--install"},
+		{Text: "Getting started", Level: 3, Line: 11, Anchor: "getting-started-1"},
+		{Text: "snake_case, `code` and Ünïcode", Level: 2, Line: 12, Anchor: "snake_case-code-and-ünïcode"},
+	}, ExtractHeadings([]byte(source)))
+}
@@ -0,0 +1,214 @@
+package preview
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+//go:embed page.tmpl
+var pageTemplate string
+
+var pageHTML = template.Must(template.New("page").Parse(pageTemplate))
+
+// page is what the page template renders: a document, a directory, or a missing file.
+type page struct {
+	Title       string
+	Breadcrumbs []breadcrumb
+	NotFound    bool
+	Orphan      bool
+	Findings    []checkdoc.Finding
+	Marks       []mark
+	Entries     []entry
+	Content     template.HTML
+	EventsPath  string
+}
+
+// breadcrumb is a parent directory of the page, from the root down.
+type breadcrumb struct {
+	Name string
+	URL  string
+}
+
+// mark is a broken link or image of the page, highlighted with the reason it is broken.
+type mark struct {
+	Destination string `json:"destination"` // As written in the document
+	Message     string `json:"message"`
+}
+
+// entry is a file or directory listed on the page of a directory.
+type entry struct {
+	Name  string
+	URL   string
+	IsDir bool
+}
+
+// serveDocument renders a document, with the findings about it and the entries of its directory, if passed.
+// The page is at relativePath, which is the directory of the document if it is served as its index.
+func (s *Server) serveDocument(w http.ResponseWriter, relativePath string, absPath string, entries []entry) {
+	s.mu.Lock()
+	source, err := s.graph.Source(absPath)
+	node, _ := s.graph.Node(absPath)
+	p := s.newPage(relativePath, node.Document.Title)
+	report, hasReport := s.result.Reports[node.RelativePath]
+	if hasReport {
+		p.addFindings(checkdoc.ListFindings(map[string]checkdoc.NodeReport{node.RelativePath: report}, nil))
+		p.Marks = s.marks(report)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+
+	content, err := markdown.RenderHTML(source)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	// Documents are trusted: their raw HTML is rendered as is
+	p.Content = template.HTML(content)
+	p.Entries = entries
+	s.render(w, http.StatusOK, p)
+}
+
+// serveDirectory renders the implicit index of a directory if it has one, below the listing of its entries.
+func (s *Server) serveDirectory(w http.ResponseWriter, relativePath string, absPath string) {
+	dirEntries, err := os.ReadDir(absPath)
+	if err != nil {
+		s.serveError(w, err)
+		return
+	}
+	var entries []entry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.Name() == ".git" || !s.isServed(filepath.Join(absPath, dirEntry.Name()), dirEntry.IsDir()) {
+			continue
+		}
+		name := dirEntry.Name()
+		if dirEntry.IsDir() {
+			name += "/"
+		}
+		entries = append(entries, entry{Name: name, URL: name, IsDir: dirEntry.IsDir()})
+	}
+	// Directories first, like git hosts list them
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		switch {
+		case a.IsDir == b.IsDir:
+			return 0
+		case a.IsDir:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	for _, index := range s.options.ImplicitIndexes {
+		indexPath := filepath.Join(absPath, index)
+		if s.isDocument(indexPath) {
+			s.serveDocument(w, relativePath, indexPath, entries)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	p := s.newPage(relativePath, "")
+	if slices.Contains(s.result.DirectoriesWithoutIndex, relativePath) {
+		p.addFindings(checkdoc.ListFindings(nil, []string{relativePath}))
+	}
+	s.mu.Unlock()
+	p.Entries = entries
+	s.render(w, http.StatusOK, p)
+}
+
+// serveNotFound tells that nothing exists at the requested path, which is what dead links lead to.
+func (s *Server) serveNotFound(w http.ResponseWriter, relativePath string) {
+	p := s.newPage(relativePath, "")
+	p.NotFound = true
+	s.render(w, http.StatusNotFound, p)
+}
+
+func (s *Server) serveError(w http.ResponseWriter, err error) {
+	slog.Warn("Could not render page", "err", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (s *Server) render(w http.ResponseWriter, status int, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := pageHTML.Execute(w, p); err != nil {
+		slog.Warn("Could not render page", "err", err)
+	}
+}
+
+// newPage returns a page for the passed path, titled after it if title is empty.
+func (s *Server) newPage(relativePath string, title string) page {
+	p := page{Title: title, EventsPath: eventsPath}
+	if p.Title == "" {
+		p.Title = relativePath
+	}
+	p.Breadcrumbs = []breadcrumb{{Name: filepath.Base(s.options.TreeRoot), URL: "/"}}
+	if relativePath != "." {
+		parts := strings.Split(relativePath, "/")
+		for i, part := range parts {
+			url := "/" + strings.Join(parts[:i+1], "/")
+			if i < len(parts)-1 {
+				url += "/"
+			}
+			p.Breadcrumbs = append(p.Breadcrumbs, breadcrumb{Name: part, URL: url})
+		}
+	}
+	return p
+}
+
+// addFindings adds the passed findings to the page: being an orphan is shown as a banner instead.
+func (p *page) addFindings(findings []checkdoc.Finding) {
+	for _, finding := range findings {
+		if finding.Kind == checkdoc.OrphanFinding {
+			p.Orphan = true
+			continue
+		}
+		p.Findings = append(p.Findings, finding)
+	}
+}
+
+// marks returns the broken links and images of a document: dead ones, links to directories without index
+// if they were checked, and links to missing anchors. The lock must be held.
+func (s *Server) marks(report checkdoc.NodeReport) []mark {
+	marks := []mark{}
+	for _, link := range checkdoc.LocalDestinations(s.options.TreeRoot, report.Node) {
+		switch {
+		case slices.Contains(report.DeadLinks, link.Target) || slices.Contains(report.DeadImageLinks, link.Target):
+			marks = append(marks, mark{
+				Destination: link.Destination,
+				Message:     fmt.Sprintf("%s does not exist%s", link.Target, checkdoc.DidYouMean(report.Suggestions[link.Target])),
+			})
+		case slices.Contains(report.DirectoryLinks, link.Target):
+			marks = append(marks, mark{
+				Destination: link.Destination,
+				Message:     fmt.Sprintf("%s is a directory without index", link.Target),
+			})
+		}
+	}
+
+	documents := make(map[string]checkdoc.LinkGraphNode)
+	for _, node := range s.graph.Nodes() {
+		documents[node.RelativePath] = node
+	}
+	for _, deadAnchor := range checkdoc.FindDeadAnchors(s.options.TreeRoot, report.Node, documents,
+		s.options.ImplicitIndexes) {
+		marks = append(marks, mark{
+			Destination: deadAnchor.Destination,
+			Message:     fmt.Sprintf("%s has no heading with anchor #%s", deadAnchor.Target, deadAnchor.Anchor),
+		})
+	}
+	return marks
+}
@@ -0,0 +1,220 @@
+// Package preview serves a documentation tree as HTML, the way the git host renders it, with the findings of
+// checkdoc overlaid on each page. Pages reload when the tree changes.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+)
+
+// Path of the server-sent events telling pages to reload, served instead of any file at that path
+const eventsPath = "/_checkdoc/events"
+
+// Server is an http.Handler rendering the documents of a tree. It keeps the link graph and findings in memory,
+// updating them as the tree changes while Watch runs.
+type Server struct {
+	checker *checkdoc.Checker
+	options checkdoc.Options // The options of the checker
+	// The tree root with symbolic links resolved, out of which no file is served
+	realTreeRoot string
+	// How often the tree is polled for changes
+	interval time.Duration
+
+	mu      sync.Mutex
+	graph   *checkdoc.IncrementalGraph
+	result  checkdoc.Result
+	updated chan struct{} // Closed, and replaced, whenever the tree changes
+	closed  chan struct{} // Closed when Watch returns
+}
+
+// NewServer returns a server for the documents of the tree of the passed checker, after building their link graph
+// and checking them once. While watching, the tree is polled for changes every interval.
+func NewServer(ctx context.Context, checker *checkdoc.Checker, interval time.Duration) (*Server, error) {
+	options := checker.Options()
+	graph, err := checkdoc.NewIncrementalGraph(options.TreeRoot, options.BaseNames, options.Extensions,
+		options.RespectGitIgnore, options.Parser)
+	if err != nil {
+		return nil, fmt.Errorf("could not build the link graph for tree root %s: %w", options.TreeRoot, err)
+	}
+	realTreeRoot, err := filepath.EvalSymlinks(options.TreeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve tree root %s: %w", options.TreeRoot, err)
+	}
+	s := &Server{
+		checker:      checker,
+		options:      options,
+		realTreeRoot: realTreeRoot,
+		interval:     interval,
+		graph:        graph,
+		updated:      make(chan struct{}),
+		closed:       make(chan struct{}),
+	}
+	s.warnParseFailures(nil)
+	if err := s.check(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// warnParseFailures warns about the passed documents that failed to parse, or about all of them if nil.
+// Their last parsed version, if any, is shown instead.
+func (s *Server) warnParseFailures(documents []string) {
+	failures := s.graph.Failures()
+	for document, err := range failures {
+		if documents == nil || slices.Contains(documents, document) {
+			slog.Warn("Could not parse document, showing its last version", "document", document, "err", err)
+		}
+	}
+}
+
+// check runs the checks on the current link graph. The lock must be held, or the server not shared yet.
+func (s *Server) check(ctx context.Context) error {
+	result, err := s.checker.Check(ctx, s.graph.Nodes())
+	if err != nil {
+		return err
+	}
+	s.result = result
+	return nil
+}
+
+// Watch polls the tree for changes until ctx is done, parsing changed documents again, checking the tree
+// and telling open pages to reload.
+func (s *Server) Watch(ctx context.Context) {
+	defer close(s.closed)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.update(ctx); err != nil && ctx.Err() == nil {
+			// Files may be in the middle of being moved: try again on the next tick
+			slog.Warn("Could not update the documentation", "err", err)
+		}
+	}
+}
+
+func (s *Server) update(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed, documents, err := s.graph.Update()
+	if err != nil || !changed {
+		return err
+	}
+	slog.Debug("Parsed changed documents", "documents", documents)
+	s.warnParseFailures(documents)
+	if err := s.check(ctx); err != nil {
+		return err
+	}
+	close(s.updated)
+	s.updated = make(chan struct{})
+	return nil
+}
+
+// ServeHTTP renders documents and directories, and serves any other file of the tree as is.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == eventsPath {
+		s.serveEvents(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	relativePath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if relativePath == "" {
+		relativePath = "."
+	}
+	if strings.HasPrefix(relativePath+"/", ".git/") {
+		s.serveNotFound(w, relativePath)
+		return
+	}
+	absPath := filepath.Join(s.options.TreeRoot, filepath.FromSlash(relativePath))
+	info, err := os.Stat(absPath)
+	if err != nil || !s.isServed(absPath, info.IsDir()) {
+		s.serveNotFound(w, relativePath)
+		return
+	}
+
+	switch {
+	case info.IsDir() && !strings.HasSuffix(r.URL.Path, "/"):
+		// Relative links of the directory's index are relative to the directory, not to its parent
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+	case info.IsDir():
+		s.serveDirectory(w, relativePath, absPath)
+	case s.isDocument(absPath):
+		s.serveDocument(w, relativePath, absPath, nil)
+	default:
+		http.ServeFile(w, r, absPath)
+	}
+}
+
+// isServed tells if the existing path can be served: it must not be ignored by git, if so configured,
+// and must not be a symbolic link to a file outside of the tree.
+func (s *Server) isServed(absPath string, isDir bool) bool {
+	realPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return false
+	}
+	if realPath != s.realTreeRoot && !strings.HasPrefix(realPath, s.realTreeRoot+string(filepath.Separator)) {
+		return false
+	}
+	if !s.options.RespectGitIgnore || absPath == s.options.TreeRoot {
+		return true
+	}
+	ignored, err := checkdoc.IsGitIgnored(s.options.TreeRoot, absPath, isDir)
+	if err != nil {
+		slog.Warn("Could not check if path is ignored by git", "path", absPath, "err", err)
+		return false
+	}
+	return !ignored
+}
+
+func (s *Server) isDocument(absPath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, isDocument := s.graph.Node(absPath)
+	return isDocument
+}
+
+// serveEvents sends an event whenever the tree changes, until the client goes away or the server stops watching.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+	for {
+		s.mu.Lock()
+		updated := s.updated
+		s.mu.Unlock()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		case <-updated:
+		}
+		if _, err := fmt.Fprint(w, "event: reload\ndata: {}\n\n"); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
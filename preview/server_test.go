@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-ch/checkdoc/checkdoc"
+	"github.com/open-ch/checkdoc/internal/testtree"
+	"github.com/open-ch/checkdoc/markdown"
+)
+
+func newTestServer(t *testing.T, files map[string]string) (*Server, string) {
+	t.Helper()
+	treeRoot := testtree.Write(t, files)
+	parser, err := markdown.NewParser(markdown.GFMBackend)
+	assert.NoError(t, err)
+	options := checkdoc.DefaultOptions(treeRoot)
+	options.RespectGitIgnore = false
+	options.Parser = parser
+	checker, err := checkdoc.NewChecker(options)
+	assert.NoError(t, err)
+	server, err := NewServer(context.Background(), checker, time.Millisecond)
+	assert.NoError(t, err)
+	return server, treeRoot
+}
+
+func get(server *Server, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func TestServer(t *testing.T) {
+	server, treeRoot := newTestServer(t, map[string]string{
+		"README.md":      "# Root\n\n[docs](docs/) [gone](docs/gone.md) [anchor](docs/README.md#nope)\n",
+		"docs/README.md": "# Docs\n\n## Usage\n",
+		"docs/orphan.md": "# Orphan\n",
+		"docs/tool.sh":   "echo tool\n",
+	})
+
+	root := get(server, "/")
+	assert.Equal(t, http.StatusOK, root.Code)
+	assert.Contains(t, root.Body.String(), `<h1 id="root">Root</h1>`)
+	assert.Contains(t, root.Body.String(), `<a href="docs/">&#128193; docs/</a>`)
+	assert.Contains(t, root.Body.String(), `<li><code>dead-link</code> docs/gone.md</li>`)
+	assert.Contains(t, root.Body.String(), `const marks = [`+
+		`{"destination":"docs/gone.md","message":"docs/gone.md does not exist"},`+
+		`{"destination":"docs/README.md#nope","message":"docs/README.md has no heading with anchor #nope"}]`)
+	assert.NotContains(t, root.Body.String(), "Orphan document")
+
+	assert.Equal(t, http.StatusMovedPermanently, get(server, "/docs").Code)
+	docs := get(server, "/docs/")
+	assert.Contains(t, docs.Body.String(), `<h2 id="usage">Usage</h2>`)
+	assert.Contains(t, docs.Body.String(), `<a href="/docs">docs</a>`)
+
+	orphan := get(server, "/docs/orphan.md")
+	assert.Contains(t, orphan.Body.String(), "Orphan document")
+	assert.Equal(t, "echo tool\n", get(server, "/docs/tool.sh").Body.String())
+	assert.Equal(t, http.StatusNotFound, get(server, "/docs/gone.md").Code)
+	assert.Equal(t, http.StatusNotFound, get(server, "/.git/config").Code)
+
+	// Changes to the tree are picked up, and tell pages to reload
+	updated := server.updated
+	assert.NoError(t, os.WriteFile(filepath.Join(treeRoot, "docs/gone.md"), []byte("# Back\n"), 0644))
+	assert.NoError(t, server.update(context.Background()))
+	assert.NotContains(t, get(server, "/").Body.String(), "dead-link")
+	select {
+	case <-updated:
+	default:
+		assert.Fail(t, "Pages were not told to reload")
+	}
+}
+
+func TestServerRefusesPaths(t *testing.T) {
+	server, treeRoot := newTestServer(t, map[string]string{
+		"README.md":       "# Root\n",
+		".gitignore":      "secrets/\n*.log\n",
+		"secrets/key.txt": "key\n",
+		"build.log":       "log\n",
+		"tool.sh":         "echo tool\n",
+	})
+	server.options.RespectGitIgnore = true
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	assert.NoError(t, os.WriteFile(outside, []byte("outside\n"), 0644))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(treeRoot, "outside.txt")))
+	assert.NoError(t, os.Symlink("tool.sh", filepath.Join(treeRoot, "inside.sh")))
+
+	assert.Equal(t, http.StatusNotFound, get(server, "/secrets/key.txt").Code)
+	assert.Equal(t, http.StatusNotFound, get(server, "/secrets/").Code)
+	assert.Equal(t, http.StatusNotFound, get(server, "/build.log").Code)
+	assert.Equal(t, http.StatusNotFound, get(server, "/outside.txt").Code)
+	assert.Equal(t, "echo tool\n", get(server, "/inside.sh").Body.String(), "Links within the tree should be served")
+
+	root := get(server, "/").Body.String()
+	assert.Contains(t, root, "tool.sh")
+	assert.NotContains(t, root, "secrets/")
+	assert.NotContains(t, root, "build.log")
+	assert.NotContains(t, root, "outside.txt")
+}